@@ -0,0 +1,178 @@
+package curl_parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime"
+	"net/url"
+	"strings"
+)
+
+// BodyKind 请求体的类型
+type BodyKind int
+
+const (
+	// BodyRaw 普通文本或二进制内容
+	BodyRaw BodyKind = iota
+	// BodyJSON JSON，Content-Type不是JSON时只识别对象和数组
+	BodyJSON
+	// BodyForm application/x-www-form-urlencoded 键值对
+	BodyForm
+	// BodyMultipart -F 构成的multipart/form-data表单，内容见 HTTPRequest.Multipart
+	BodyMultipart
+	// BodyFile 引用了没有读取的文件，例如未配置文件系统时的 --data-binary @file
+	BodyFile
+	// BodyStdin 从标准输入读取，例如 -d @-、-T -
+	BodyStdin
+)
+
+// String 返回请求体类型的名称
+func (k BodyKind) String() string {
+	switch k {
+	case BodyJSON:
+		return "json"
+	case BodyForm:
+		return "form"
+	case BodyMultipart:
+		return "multipart"
+	case BodyFile:
+		return "file"
+	case BodyStdin:
+		return "stdin"
+	default:
+		return "raw"
+	}
+}
+
+// formContentType -d 等选项默认使用的Content-Type
+const formContentType = "application/x-www-form-urlencoded"
+
+// Body 分类后的请求体
+type Body struct {
+	Kind BodyKind
+	// 解析时的原始内容，与解析得到的 HTTPRequest.Body 相同，ToHTTPRequest 发送的是 HTTPRequest.Body；
+	// multipart表单为空，需要通过 HTTPRequest.MultipartBody 生成
+	Raw []byte
+	// 生成请求体的第一个选项，保持命令中的写法，例如 "-d"、"--json"、"-F"、"-T"
+	Option string
+	// 请求的Content-Type，来自 -H 或curl的默认值，可能为空
+	ContentType string
+	// Kind 为 BodyFile 时引用的文件路径
+	File string
+	// Kind 为 BodyFile 或 BodyStdin 时生成请求体的所有参数，按出现顺序排列，
+	// 例如 -d @a -d b 为两个参数，Generate 按这些参数生成命令
+	Args []BodyArg
+}
+
+// BodyArg 生成请求体的一个参数
+type BodyArg struct {
+	// 选项在命令中的写法，例如 "-d"、"--data-binary"、"-T"
+	Option string
+	// 选项的参数原文，文件引用保持 @file 的写法
+	Value string
+}
+
+// JSON 解码JSON请求体，数字解码为 json.Number 以保留精度
+func (b *Body) JSON() (any, error) {
+	var value any
+	decoder := json.NewDecoder(bytes.NewReader(b.Raw))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// Form 解析 application/x-www-form-urlencoded 请求体
+func (b *Body) Form() (url.Values, error) {
+	return url.ParseQuery(string(b.Raw))
+}
+
+// extractTypedBody 根据生成请求体的选项、Content-Type和内容对请求体分类
+// 没有请求体时 TypedBody 为nil。
+func (cp *CurlParser) extractTypedBody(args []curlArg, req *HTTPRequest) {
+	if req.IsMultipart {
+		arg, _ := firstArg(args, "form", "form-string")
+		req.TypedBody = &Body{Kind: BodyMultipart, Option: arg.flag, ContentType: "multipart/form-data"}
+		return
+	}
+
+	var sources []curlArg
+	if upload, ok := firstArg(args, "upload-file"); ok {
+		sources = []curlArg{upload}
+	} else if !flagEnabled(args, "get") {
+		for _, arg := range args {
+			if arg.is(dataOptions...) {
+				sources = append(sources, arg)
+			}
+		}
+	}
+	if len(sources) == 0 {
+		return
+	}
+
+	body := &Body{
+		Raw:         []byte(req.Body),
+		Option:      sources[0].flag,
+		ContentType: req.HeaderList.Get("Content-Type"),
+	}
+	req.TypedBody = body
+
+	// 没有读取的文件引用
+	for _, ref := range req.UnresolvedFiles {
+		for _, source := range sources {
+			if ref.Index != source.valueIndex {
+				continue
+			}
+			if ref.Path == "-" {
+				body.Kind = BodyStdin
+			} else {
+				body.Kind, body.File = BodyFile, ref.Path
+			}
+			// 多个参数连接后无法再区分文件引用和普通内容，需要保留每个参数
+			for _, source := range sources {
+				body.Args = append(body.Args, BodyArg{Option: source.flag, Value: source.value})
+			}
+			return
+		}
+	}
+
+	// --json 的Content-Type已经在 extractHeaders 中添加，-d 等选项没有指定时curl使用表单类型
+	explicit := body.ContentType != ""
+	if !explicit && sources[0].name != "upload-file" && !req.HeaderRemoved("Content-Type") {
+		body.ContentType = formContentType
+	}
+	body.Kind = classifyBody(body.ContentType, explicit, body.Raw)
+}
+
+// classifyBody 根据Content-Type和内容判断请求体类型，explicit表示Content-Type来自请求头
+// 没有指定Content-Type时，内容是合法的JSON对象或数组也会识别为JSON，例如常见的 -d '{"a":1}'。
+func classifyBody(contentType string, explicit bool, raw []byte) BodyKind {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	trimmed := bytes.TrimSpace(raw)
+	jsonType := mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+	jsonValue := len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[')
+
+	switch {
+	case json.Valid(trimmed) && (jsonType || jsonValue && !explicit):
+		return BodyJSON
+	case mediaType == formContentType && isFormData(string(raw)):
+		return BodyForm
+	default:
+		return BodyRaw
+	}
+}
+
+// isFormData 判断内容是否为 name=value&name=value 形式的键值对
+func isFormData(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, pair := range strings.Split(s, "&") {
+		if !strings.Contains(pair, "=") {
+			return false
+		}
+	}
+	_, err := url.ParseQuery(s)
+	return err == nil
+}
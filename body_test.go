@@ -0,0 +1,188 @@
+package curl_parser
+
+import (
+	"encoding/json"
+	"net/url"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+func TestCurlParser_TypedBody(t *testing.T) {
+	fsys := fstest.MapFS{
+		"body.json": {Data: []byte(`{"a":1}`)},
+	}
+
+	tests := []struct {
+		name            string
+		cmd             string
+		fsys            fstest.MapFS
+		wantNil         bool
+		wantArgs        []BodyArg
+		wantKind        BodyKind
+		wantOption      string
+		wantContentType string
+		wantFile        string
+	}{
+		{
+			name:    "No body",
+			cmd:     `curl https://a.com`,
+			wantNil: true,
+		},
+		{
+			name:    "Get moves data into the URL",
+			cmd:     `curl -G -d a=1 https://a.com`,
+			wantNil: true,
+		},
+		{
+			name:            "Form data",
+			cmd:             `curl -d a=1 -d 'b=x%20y' https://a.com`,
+			wantKind:        BodyForm,
+			wantOption:      "-d",
+			wantContentType: "application/x-www-form-urlencoded",
+		},
+		{
+			name:            "JSON without content type",
+			cmd:             `curl --data-raw '[1, 2]' https://a.com`,
+			wantKind:        BodyJSON,
+			wantOption:      "--data-raw",
+			wantContentType: "application/x-www-form-urlencoded",
+		},
+		{
+			name:            "JSON option",
+			cmd:             `curl --json '{"a":1}' https://a.com`,
+			wantKind:        BodyJSON,
+			wantOption:      "--json",
+			wantContentType: "application/json",
+		},
+		{
+			name:            "JSON content type with scalar",
+			cmd:             `curl -H 'Content-Type: application/problem+json; charset=utf-8' -d '"x"' https://a.com`,
+			wantKind:        BodyJSON,
+			wantOption:      "-d",
+			wantContentType: "application/problem+json; charset=utf-8",
+		},
+		{
+			name:            "JSON text with explicit text type",
+			cmd:             `curl -H 'Content-Type: text/plain' -d '{"a":1}' https://a.com`,
+			wantKind:        BodyRaw,
+			wantOption:      "-d",
+			wantContentType: "text/plain",
+		},
+		{
+			name:            "Raw text",
+			cmd:             `curl -d 'hello world' https://a.com`,
+			wantKind:        BodyRaw,
+			wantOption:      "-d",
+			wantContentType: "application/x-www-form-urlencoded",
+		},
+		{
+			name:            "Multipart",
+			cmd:             `curl --form-string a=1 -F b=2 https://a.com`,
+			wantKind:        BodyMultipart,
+			wantOption:      "--form-string",
+			wantContentType: "multipart/form-data",
+		},
+		{
+			name:       "Unresolved file",
+			cmd:        `curl --data-binary @body.json https://a.com`,
+			wantKind:   BodyFile,
+			wantOption: "--data-binary",
+			wantFile:   "body.json",
+			wantArgs:   []BodyArg{{Option: "--data-binary", Value: "@body.json"}},
+		},
+		{
+			name:            "Resolved file",
+			cmd:             `curl --data-binary @body.json -H 'Content-Type: application/json' https://a.com`,
+			fsys:            fsys,
+			wantKind:        BodyJSON,
+			wantOption:      "--data-binary",
+			wantContentType: "application/json",
+		},
+		{
+			name:       "Stdin",
+			cmd:        `curl -d a=1 -d @- https://a.com`,
+			wantKind:   BodyStdin,
+			wantOption: "-d",
+			wantArgs:   []BodyArg{{Option: "-d", Value: "a=1"}, {Option: "-d", Value: "@-"}},
+		},
+		{
+			name:       "Upload from stdin",
+			cmd:        `curl -T - https://a.com/`,
+			wantKind:   BodyStdin,
+			wantOption: "-T",
+			wantArgs:   []BodyArg{{Option: "-T", Value: "-"}},
+		},
+		{
+			name:       "Uploaded file",
+			cmd:        `curl -T body.json https://a.com/`,
+			fsys:       fsys,
+			wantKind:   BodyJSON,
+			wantOption: "-T",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts []Option
+			if tt.fsys != nil {
+				opts = append(opts, WithFS(tt.fsys))
+			}
+			req, err := NewCurlParser(tt.cmd, opts...).Parse()
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			body := req.TypedBody
+			if tt.wantNil {
+				if body != nil {
+					t.Errorf("TypedBody = %+v, want nil", body)
+				}
+				return
+			}
+			if body == nil {
+				t.Fatalf("TypedBody = nil")
+			}
+			if body.Kind != tt.wantKind {
+				t.Errorf("Kind = %s, want %s", body.Kind, tt.wantKind)
+			}
+			if body.Option != tt.wantOption {
+				t.Errorf("Option = %s, want %s", body.Option, tt.wantOption)
+			}
+			if body.ContentType != tt.wantContentType {
+				t.Errorf("ContentType = %s, want %s", body.ContentType, tt.wantContentType)
+			}
+			if body.File != tt.wantFile {
+				t.Errorf("File = %s, want %s", body.File, tt.wantFile)
+			}
+			if !reflect.DeepEqual(body.Args, tt.wantArgs) {
+				t.Errorf("Args = %+v, want %+v", body.Args, tt.wantArgs)
+			}
+			if tt.wantKind != BodyMultipart && string(body.Raw) != req.Body {
+				t.Errorf("Raw = %q, want %q", body.Raw, req.Body)
+			}
+		})
+	}
+}
+
+func TestBody_Views(t *testing.T) {
+	jsonBody := &Body{Raw: []byte(`{"id":12345678901234567890,"tags":["a"]}`)}
+	value, err := jsonBody.JSON()
+	if err != nil {
+		t.Fatalf("JSON() error = %v", err)
+	}
+	want := map[string]any{"id": json.Number("12345678901234567890"), "tags": []any{"a"}}
+	if !reflect.DeepEqual(value, want) {
+		t.Errorf("JSON() = %v, want %v", value, want)
+	}
+	if _, err := (&Body{Raw: []byte("a=1")}).JSON(); err == nil {
+		t.Errorf("JSON() error = nil, want error")
+	}
+
+	form, err := (&Body{Raw: []byte("a=1&b=x%20y&a=2")}).Form()
+	if err != nil {
+		t.Fatalf("Form() error = %v", err)
+	}
+	if wantForm := (url.Values{"a": {"1", "2"}, "b": {"x y"}}); !reflect.DeepEqual(form, wantForm) {
+		t.Errorf("Form() = %v, want %v", form, wantForm)
+	}
+}
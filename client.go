@@ -0,0 +1,109 @@
+package curl_parser
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// NewHTTPClient 根据解析出的传输选项创建 *http.Client
+// 支持 -x 指定的 http、https、socks5 和 socks5h 代理，--connect-timeout、--max-time、-k、--cacert，
+// 以及 -L 和 --max-redirs 的重定向策略。没有 -L 时与curl一样不跟随重定向，直接返回3xx响应。
+// --cacert 的文件通过解析时 WithFS 指定的文件系统读取，没有指定时从本地文件系统读取。
+// 每次调用都会创建新的Transport，不再使用时需要调用 client.CloseIdleConnections()。
+func NewHTTPClient(r *HTTPRequest) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// curl只有在使用 --compressed 时才会发送 Accept-Encoding
+	transport.DisableCompression = true
+
+	if r.Proxy != "" {
+		proxyURL, err := parseProxyURL(r.Proxy)
+		if err != nil {
+			return nil, err
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	if r.ConnectTimeout > 0 {
+		dialer := &net.Dialer{
+			Timeout:   time.Duration(r.ConnectTimeout) * time.Second,
+			KeepAlive: 30 * time.Second,
+		}
+		transport.DialContext = dialer.DialContext
+	}
+
+	if r.Insecure || r.CACert != "" {
+		tlsConfig := &tls.Config{InsecureSkipVerify: r.Insecure}
+		if r.CACert != "" {
+			pool, err := r.loadCACert()
+			if err != nil {
+				return nil, err
+			}
+			tlsConfig.RootCAs = pool
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	return &http.Client{
+		Transport:     transport,
+		Timeout:       time.Duration(r.MaxTime) * time.Second,
+		CheckRedirect: r.checkRedirect,
+	}, nil
+}
+
+// checkRedirect 与curl的 -L 和 --max-redirs 行为一致
+func (r *HTTPRequest) checkRedirect(req *http.Request, via []*http.Request) error {
+	if !r.FollowRedirects {
+		return http.ErrUseLastResponse
+	}
+	if r.MaxRedirects >= 0 && len(via) > r.MaxRedirects {
+		return fmt.Errorf("重定向次数超过 %d 次", r.MaxRedirects)
+	}
+	return nil
+}
+
+// parseProxyURL 解析 -x 的参数，没有协议时与curl一样默认使用http
+func parseProxyURL(proxy string) (*url.URL, error) {
+	if !strings.Contains(proxy, "://") {
+		proxy = "http://" + proxy
+	}
+	proxyURL, err := url.Parse(proxy)
+	if err != nil {
+		return nil, fmt.Errorf("无效的代理地址 %q: %w", proxy, err)
+	}
+	switch proxyURL.Scheme {
+	case "http", "https", "socks5", "socks5h":
+		return proxyURL, nil
+	default:
+		return nil, fmt.Errorf("不支持的代理协议 %s", proxyURL.Scheme)
+	}
+}
+
+// loadCACert 读取 --cacert 指定的PEM格式证书
+func (r *HTTPRequest) loadCACert() (*x509.CertPool, error) {
+	var (
+		data []byte
+		err  error
+	)
+	if r.fsys != nil {
+		data, err = fs.ReadFile(r.fsys, fsPath(r.CACert))
+	} else {
+		data, err = os.ReadFile(r.CACert)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取CA证书 %s 失败: %w", r.CACert, err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("CA证书 %s 中没有有效的PEM证书", r.CACert)
+	}
+	return pool, nil
+}
@@ -1,7 +1,6 @@
 package curl_parser
 
 import (
-	"strings"
 	"testing"
 )
 
@@ -531,6 +530,58 @@ func TestCurlParser_Parse(t *testing.T) {
 			wantFollowRedirects: true,
 			wantErr:             false,
 		},
+		{
+			name:        "Option-like text inside header values",
+			curlCommand: `curl -H "X-Trace: a-Lb" -H 'X-Opt: -d x --location' https://httpbin.org/get`,
+			wantMethod:  "GET",
+			wantURL:     "https://httpbin.org/get",
+			wantBaseURL: "https://httpbin.org",
+			wantPath:    "/get",
+			wantHeaders: map[string]string{
+				"X-Trace": "a-Lb",
+				"X-Opt":   "-d x --location",
+			},
+			wantBody:          "",
+			wantQuery:         map[string]string{},
+			wantParsedCookies: map[string]string{},
+			wantErr:           false,
+		},
+		{
+			name:        "Adjacent quoted segments and combined short options",
+			curlCommand: "curl -sSL -XPOST \\\n  -H 'X-Name: '\"\\$HOME\" \\\n  -d 'a'\"b\"c https://httpbin.org/post",
+			wantMethod:  "POST",
+			wantURL:     "https://httpbin.org/post",
+			wantBaseURL: "https://httpbin.org",
+			wantPath:    "/post",
+			wantHeaders: map[string]string{
+				"X-Name": "$HOME",
+			},
+			wantBody:            "abc",
+			wantQuery:           map[string]string{},
+			wantParsedCookies:   map[string]string{},
+			wantFollowRedirects: true,
+			wantErr:             false,
+		},
+		{
+			name:        "Chrome copy as cURL (bash) with ANSI-C quoting",
+			curlCommand: "curl 'https://httpbin.org/post' \\\n  -H 'content-type: application/json' \\\n  --data-raw $'{\"a\":\"line\\nbreak\",\"b\":\"it\\'s\",\"c\":\"\\u00e9\"}' \\\n  --compressed",
+			wantMethod:  "POST",
+			wantURL:     "https://httpbin.org/post",
+			wantBaseURL: "https://httpbin.org",
+			wantPath:    "/post",
+			wantHeaders: map[string]string{
+				"content-type": "application/json",
+			},
+			wantBody:          "{\"a\":\"line\nbreak\",\"b\":\"it's\",\"c\":\"é\"}",
+			wantQuery:         map[string]string{},
+			wantParsedCookies: map[string]string{},
+			wantErr:           false,
+		},
+		{
+			name:        "Unterminated quote",
+			curlCommand: `curl -H 'Accept: */* https://httpbin.org/get`,
+			wantErr:     true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -687,15 +738,13 @@ func TestCurlParser_extractURL(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			cp := NewCurlParser(tt.curlCommand)
-			// Clean the command like in Parse method
-			cmd := strings.ReplaceAll(cp.curlCommand, "\\\n", " ")
-			cmd = strings.ReplaceAll(cmd, "\\", "")
-			cmd = strings.TrimSpace(cmd)
-			if strings.HasPrefix(cmd, "curl ") {
-				cmd = strings.TrimPrefix(cmd, "curl ")
+			// Split the command into argv like in Parse method
+			args, err := cp.parseArgs(&HTTPRequest{})
+			if err != nil {
+				t.Fatalf("CurlParser.parseArgs() error = %v", err)
 			}
 
-			got, err := cp.extractURL(cmd)
+			got, err := cp.extractURL(args)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("CurlParser.extractURL() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -743,20 +792,23 @@ func TestCurlParser_extractMethod(t *testing.T) {
 			curlCommand: `curl https://httpbin.org/get`,
 			want:        "GET",
 		},
+		{
+			name:        "Data flag inside header value",
+			curlCommand: `curl -H "X-Opt: -d" https://httpbin.org/get`,
+			want:        "GET",
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			cp := NewCurlParser(tt.curlCommand)
-			// Clean the command like in Parse method
-			cmd := strings.ReplaceAll(cp.curlCommand, "\\\n", " ")
-			cmd = strings.ReplaceAll(cmd, "\\", "")
-			cmd = strings.TrimSpace(cmd)
-			if strings.HasPrefix(cmd, "curl ") {
-				cmd = strings.TrimPrefix(cmd, "curl ")
+			// Split the command into argv like in Parse method
+			args, err := cp.parseArgs(&HTTPRequest{})
+			if err != nil {
+				t.Fatalf("CurlParser.parseArgs() error = %v", err)
 			}
 
-			if got := cp.extractMethod(cmd); got != tt.want {
+			if got := cp.extractMethod(args); got != tt.want {
 				t.Errorf("CurlParser.extractMethod() = %v, want %v", got, tt.want)
 			}
 		})
@@ -793,15 +845,17 @@ func TestCurlParser_extractBody(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			cp := NewCurlParser(tt.curlCommand)
-			// Clean the command like in Parse method
-			cmd := strings.ReplaceAll(cp.curlCommand, "\\\n", " ")
-			cmd = strings.ReplaceAll(cmd, "\\", "")
-			cmd = strings.TrimSpace(cmd)
-			if strings.HasPrefix(cmd, "curl ") {
-				cmd = strings.TrimPrefix(cmd, "curl ")
+			// Split the command into argv like in Parse method
+			args, err := cp.parseArgs(&HTTPRequest{})
+			if err != nil {
+				t.Fatalf("CurlParser.parseArgs() error = %v", err)
 			}
 
-			if got := cp.extractBody(cmd); got != tt.want {
+			got, err := cp.extractBody(args, &HTTPRequest{})
+			if err != nil {
+				t.Fatalf("CurlParser.extractBody() error = %v", err)
+			}
+			if got != tt.want {
 				t.Errorf("CurlParser.extractBody() = %v, want %v", got, tt.want)
 			}
 		})
@@ -0,0 +1,85 @@
+package curl_parser
+
+import (
+	"strings"
+)
+
+// jsonHeaders --json 会让curl添加的请求头，-H 指定的同名请求头优先
+var jsonHeaders = []Header{
+	{Name: "Content-Type", Value: "application/json"},
+	{Name: "Accept", Value: "application/json"},
+}
+
+// crlfStripper 去掉 -d @file 读取到的回车和换行
+var crlfStripper = strings.NewReplacer("\r", "", "\n", "")
+
+// dataValue 按curl的规则计算单个数据参数实际发送的内容
+// -d、--data-ascii、--data-binary 和 --json 以 @ 开头时读取文件，其中 -d 和 --data-ascii 会去掉文件中的回车和换行；
+// --data-raw 不处理 @；--data-urlencode 见 urlencodeValue。
+// 文件没有读取时保留参数原文。
+func (cp *CurlParser) dataValue(req *HTTPRequest, arg curlArg) (string, error) {
+	switch {
+	case arg.name == "data-raw":
+		return arg.value, nil
+	case arg.name == "data-urlencode":
+		return cp.urlencodeValue(req, arg)
+	case !strings.HasPrefix(arg.value, "@"):
+		return arg.value, nil
+	}
+
+	data, ok, err := cp.loadFile(req, arg, arg.value[1:])
+	if err != nil || !ok {
+		return arg.value, err
+	}
+	if arg.is("data", "data-ascii") {
+		return crlfStripper.Replace(string(data)), nil
+	}
+	return string(data), nil
+}
+
+// urlencodeValue 计算 --data-urlencode 的内容，支持curl的五种写法：
+//
+//	content    对content编码
+//	=content   对content编码，开头的=不发送
+//	name=content  发送 name=编码后的content，name不编码
+//	@file      对文件内容编码
+//	name@file  发送 name=编码后的文件内容
+//
+// 与curl一致，先查找 =，没有 = 时才查找 @。
+func (cp *CurlParser) urlencodeValue(req *HTTPRequest, arg curlArg) (string, error) {
+	var (
+		name    string
+		content = arg.value
+	)
+	if i := strings.IndexByte(arg.value, '='); i >= 0 {
+		name, content = arg.value[:i], arg.value[i+1:]
+	} else if i := strings.IndexByte(arg.value, '@'); i >= 0 {
+		data, ok, err := cp.loadFile(req, arg, arg.value[i+1:])
+		if err != nil || !ok {
+			return arg.value, err
+		}
+		name, content = arg.value[:i], string(data)
+	}
+
+	if name == "" {
+		return urlEscape(content), nil
+	}
+	return name + "=" + urlEscape(content), nil
+}
+
+// urlEscape 与 curl_easy_escape 相同，只保留字母、数字和 -._~，其余字节（包括空格）都编码为 %XX
+func urlEscape(s string) string {
+	const hex = "0123456789ABCDEF"
+	var buf strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '-' || c == '.' || c == '_' || c == '~' {
+			buf.WriteByte(c)
+			continue
+		}
+		buf.WriteByte('%')
+		buf.WriteByte(hex[c>>4])
+		buf.WriteByte(hex[c&0x0f])
+	}
+	return buf.String()
+}
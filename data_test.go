@@ -0,0 +1,417 @@
+package curl_parser
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+func TestCurlParser_DataOptions(t *testing.T) {
+	fsys := fstest.MapFS{
+		"body.txt": {Data: []byte("a=1\r\nb=2\n")},
+		"q.txt":    {Data: []byte("hello world\n")},
+		"data.json": {Data: []byte(`{"a":
+1}`)},
+	}
+
+	tests := []struct {
+		name     string
+		cmd      string
+		wantBody string
+		wantCode ErrorCode
+	}{
+		{
+			name:     "Data strips CR and LF from file",
+			cmd:      `curl -d @body.txt https://a.com`,
+			wantBody: "a=1b=2",
+		},
+		{
+			name:     "Data ascii strips CR and LF from file",
+			cmd:      `curl --data-ascii @body.txt https://a.com`,
+			wantBody: "a=1b=2",
+		},
+		{
+			name:     "Data binary keeps file content",
+			cmd:      `curl --data-binary @body.txt https://a.com`,
+			wantBody: "a=1\r\nb=2\n",
+		},
+		{
+			name:     "Data raw does not read files",
+			cmd:      `curl --data-raw @body.txt https://a.com`,
+			wantBody: "@body.txt",
+		},
+		{
+			name:     "Data keeps newlines in literal values",
+			cmd:      "curl -d $'a\\nb' https://a.com",
+			wantBody: "a\nb",
+		},
+		{
+			name:     "Urlencode content",
+			cmd:      `curl --data-urlencode 'hello world&~*' https://a.com`,
+			wantBody: "hello%20world%26~%2A",
+		},
+		{
+			name:     "Urlencode leading equals",
+			cmd:      `curl --data-urlencode '=a=b c' https://a.com`,
+			wantBody: "a%3Db%20c",
+		},
+		{
+			name:     "Urlencode name and content",
+			cmd:      `curl --data-urlencode 'q=a@b c' https://a.com`,
+			wantBody: "q=a%40b%20c",
+		},
+		{
+			name:     "Urlencode file",
+			cmd:      `curl --data-urlencode @q.txt https://a.com`,
+			wantBody: "hello%20world%0A",
+		},
+		{
+			name:     "Urlencode name and file",
+			cmd:      `curl --data-urlencode q@q.txt https://a.com`,
+			wantBody: "q=hello%20world%0A",
+		},
+		{
+			name:     "Urlencode UTF-8",
+			cmd:      `curl --data-urlencode 'q=中' https://a.com`,
+			wantBody: "q=%E4%B8%AD",
+		},
+		{
+			name:     "Json reads file as is",
+			cmd:      `curl --json @data.json https://a.com`,
+			wantBody: "{\"a\":\n1}",
+		},
+		{
+			name:     "Json pieces are concatenated",
+			cmd:      `curl --json '{"a":' --json '1}' https://a.com`,
+			wantBody: `{"a":1}`,
+		},
+		{
+			name:     "Missing file",
+			cmd:      `curl --data-binary @missing.bin https://a.com`,
+			wantCode: CodeFileRead,
+		},
+		{
+			name:     "Missing urlencode file",
+			cmd:      `curl --data-urlencode q@missing.txt https://a.com`,
+			wantCode: CodeFileRead,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := NewCurlParser(tt.cmd, WithFS(fsys)).Parse()
+			if tt.wantCode != "" {
+				var perr *ParseError
+				if !errors.As(err, &perr) || perr.Code != tt.wantCode {
+					t.Fatalf("Parse() error = %v, want code %s", err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if req.Body != tt.wantBody {
+				t.Errorf("Body = %q, want %q", req.Body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestCurlParser_MultipleData(t *testing.T) {
+	tests := []struct {
+		name string
+		cmd  string
+		want string
+	}{
+		{
+			name: "Data arguments joined with ampersand",
+			cmd:  `curl -d a=1 -d b=2 https://a.com`,
+			want: "a=1&b=2",
+		},
+		{
+			name: "Mixed options and quoting styles",
+			cmd:  `curl -d 'a=1' --data-raw "b=2" --data-urlencode c='x y' --data-binary $'d=4' -d e\=5 https://a.com`,
+			want: "a=1&b=2&c=x%20y&d=4&e=5",
+		},
+		{
+			name: "Json pieces have no separator",
+			cmd:  `curl -d a=1 --json '{"b":' --json '2}' https://a.com`,
+			want: `a=1{"b":2}`,
+		},
+		{
+			name: "Data after json uses separator",
+			cmd:  `curl --json '{}' -d a=1 https://a.com`,
+			want: `{}&a=1`,
+		},
+		{
+			name: "Separator only after non-empty data",
+			cmd:  `curl -d '' -d a=1 -d '' https://a.com`,
+			want: "a=1&",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := NewCurlParser(tt.cmd).Parse()
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if req.Body != tt.want {
+				t.Errorf("Body = %q, want %q", req.Body, tt.want)
+			}
+		})
+	}
+}
+
+func TestCurlParser_DataUnresolvedFiles(t *testing.T) {
+	tests := []struct {
+		name     string
+		cmd      string
+		wantBody string
+		want     FileReference
+	}{
+		{
+			name:     "Data file",
+			cmd:      `curl -d @body.txt https://a.com`,
+			wantBody: "@body.txt",
+			want:     FileReference{Option: "-d", Path: "body.txt", Index: 2},
+		},
+		{
+			name:     "Urlencode file",
+			cmd:      `curl --data-urlencode q@q.txt https://a.com`,
+			wantBody: "q@q.txt",
+			want:     FileReference{Option: "--data-urlencode", Path: "q.txt", Index: 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := NewCurlParser(tt.cmd).Parse()
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if req.Body != tt.wantBody {
+				t.Errorf("Body = %q, want the reference kept as is", req.Body)
+			}
+			if !reflect.DeepEqual(req.UnresolvedFiles, []FileReference{tt.want}) {
+				t.Errorf("UnresolvedFiles = %+v, want %+v", req.UnresolvedFiles, tt.want)
+			}
+		})
+	}
+}
+
+func TestCurlParser_JSONHeaders(t *testing.T) {
+	tests := []struct {
+		name string
+		cmd  string
+		want HeaderList
+	}{
+		{
+			name: "Default JSON headers",
+			cmd:  `curl --json '{}' https://a.com`,
+			want: HeaderList{
+				{Name: "Content-Type", Value: "application/json"},
+				{Name: "Accept", Value: "application/json"},
+			},
+		},
+		{
+			name: "User headers win",
+			cmd:  `curl --json '{}' -H 'content-type: application/vnd.api+json' -H 'Accept:' https://a.com`,
+			want: HeaderList{
+				{Name: "content-type", Value: "application/vnd.api+json"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := NewCurlParser(tt.cmd).Parse()
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if !reflect.DeepEqual(req.HeaderList, tt.want) {
+				t.Errorf("HeaderList = %v, want %v", req.HeaderList, tt.want)
+			}
+			if req.Method != "POST" {
+				t.Errorf("Method = %s, want POST", req.Method)
+			}
+		})
+	}
+}
+
+func TestCurlParser_Get(t *testing.T) {
+	tests := []struct {
+		name       string
+		cmd        string
+		wantMethod string
+		wantURL    string
+		wantQuery  map[string]string
+	}{
+		{
+			name:       "Urlencoded data moves into the query",
+			cmd:        `curl -G --data-urlencode "q=hello world" -d lang=go https://api/search`,
+			wantMethod: "GET",
+			wantURL:    "https://api/search?q=hello%20world&lang=go",
+			wantQuery:  map[string]string{"q": "hello world", "lang": "go"},
+		},
+		{
+			name:       "Plain data is escaped",
+			cmd:        "curl -G -d 'q=hello world' -d 'tag=a#b' -d $'c=\\x01ä' https://api/search",
+			wantMethod: "GET",
+			wantURL:    "https://api/search?q=hello%20world&tag=a%23b&c=%01%C3%A4",
+			wantQuery:  map[string]string{"q": "hello world", "tag": "a#b", "c": "\x01ä"},
+		},
+		{
+			name:       "Existing query and fragment",
+			cmd:        `curl --get -d b=2 'https://a.com/p?a=1#top'`,
+			wantMethod: "GET",
+			wantURL:    "https://a.com/p?a=1&b=2#top",
+			wantQuery:  map[string]string{"a": "1", "b": "2"},
+		},
+		{
+			name:       "Head with get",
+			cmd:        `curl -G -I -d a=1 https://a.com`,
+			wantMethod: "HEAD",
+			wantURL:    "https://a.com?a=1",
+			wantQuery:  map[string]string{"a": "1"},
+		},
+		{
+			name:       "Explicit method wins",
+			cmd:        `curl -G -X POST -d a=1 https://a.com`,
+			wantMethod: "POST",
+			wantURL:    "https://a.com?a=1",
+			wantQuery:  map[string]string{"a": "1"},
+		},
+		{
+			name:       "Get without data",
+			cmd:        `curl -G https://a.com`,
+			wantMethod: "GET",
+			wantURL:    "https://a.com",
+			wantQuery:  map[string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := NewCurlParser(tt.cmd).Parse()
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if req.Method != tt.wantMethod {
+				t.Errorf("Method = %s, want %s", req.Method, tt.wantMethod)
+			}
+			if req.URL != tt.wantURL {
+				t.Errorf("URL = %s, want %s", req.URL, tt.wantURL)
+			}
+			if !reflect.DeepEqual(req.Query, tt.wantQuery) {
+				t.Errorf("Query = %v, want %v", req.Query, tt.wantQuery)
+			}
+			if req.Body != "" {
+				t.Errorf("Body = %q, want empty", req.Body)
+			}
+			for _, w := range req.Warnings {
+				if w.Severity == SeverityWarning {
+					t.Errorf("unexpected warning: %v", w)
+				}
+			}
+		})
+	}
+}
+
+func TestCurlParser_HeadAndUpload(t *testing.T) {
+	fsys := fstest.MapFS{
+		"dir/report 1.csv": {Data: []byte("a,b\r\n")},
+	}
+
+	tests := []struct {
+		name           string
+		cmd            string
+		wantMethod     string
+		wantURL        string
+		wantPath       string
+		wantBody       string
+		wantUpload     string
+		wantUnresolved []FileReference
+	}{
+		{
+			name:       "Head",
+			cmd:        `curl -I https://a.com/p`,
+			wantMethod: "HEAD",
+			wantURL:    "https://a.com/p",
+			wantPath:   "/p",
+		},
+		{
+			name:       "Upload appends file name to directory URL",
+			cmd:        `curl -T 'dir/report 1.csv' 'https://a.com/up/?x=1'`,
+			wantMethod: "PUT",
+			wantURL:    "https://a.com/up/report%201.csv?x=1",
+			wantPath:   "/up/report 1.csv",
+			wantBody:   "a,b\r\n",
+			wantUpload: "dir/report 1.csv",
+		},
+		{
+			name:       "Upload appends file name to URL without path",
+			cmd:        `curl --upload-file 'dir/report 1.csv' https://a.com`,
+			wantMethod: "PUT",
+			wantURL:    "https://a.com/report%201.csv",
+			wantPath:   "/report 1.csv",
+			wantBody:   "a,b\r\n",
+			wantUpload: "dir/report 1.csv",
+		},
+		{
+			name:       "Upload keeps URL with file name",
+			cmd:        `curl -T 'dir/report 1.csv' https://a.com/up/r.csv`,
+			wantMethod: "PUT",
+			wantURL:    "https://a.com/up/r.csv",
+			wantPath:   "/up/r.csv",
+			wantBody:   "a,b\r\n",
+			wantUpload: "dir/report 1.csv",
+		},
+		{
+			name:           "Upload from stdin",
+			cmd:            `curl -T - https://a.com/up/`,
+			wantMethod:     "PUT",
+			wantURL:        "https://a.com/up/",
+			wantPath:       "/up/",
+			wantUpload:     "-",
+			wantUnresolved: []FileReference{{Option: "-T", Path: "-", Index: 2}},
+		},
+		{
+			name:       "Explicit method keeps upload body",
+			cmd:        `curl -X POST -T 'dir/report 1.csv' https://a.com/up/r.csv`,
+			wantMethod: "POST",
+			wantURL:    "https://a.com/up/r.csv",
+			wantPath:   "/up/r.csv",
+			wantBody:   "a,b\r\n",
+			wantUpload: "dir/report 1.csv",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := NewCurlParser(tt.cmd, WithFS(fsys)).Parse()
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if req.Method != tt.wantMethod {
+				t.Errorf("Method = %s, want %s", req.Method, tt.wantMethod)
+			}
+			if req.URL != tt.wantURL {
+				t.Errorf("URL = %s, want %s", req.URL, tt.wantURL)
+			}
+			if req.Path != tt.wantPath {
+				t.Errorf("Path = %s, want %s", req.Path, tt.wantPath)
+			}
+			if req.Body != tt.wantBody {
+				t.Errorf("Body = %q, want %q", req.Body, tt.wantBody)
+			}
+			if req.UploadFile != tt.wantUpload {
+				t.Errorf("UploadFile = %q, want %q", req.UploadFile, tt.wantUpload)
+			}
+			if !reflect.DeepEqual(req.UnresolvedFiles, tt.wantUnresolved) {
+				t.Errorf("UnresolvedFiles = %+v, want %+v", req.UnresolvedFiles, tt.wantUnresolved)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package curl_parser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// conflictingOptions 不能同时使用的选项组，curl遇到这些组合会报错
+var conflictingOptions = []struct {
+	first  []string
+	second []string
+	// 使用 -G 时数据会放到URL中，不再冲突
+	allowedWithGet bool
+}{
+	{first: []string{"get"}, second: []string{"form", "form-string"}},
+	{first: []string{"form", "form-string"}, second: dataOptions},
+	{first: []string{"head"}, second: []string{"form", "form-string"}},
+	{first: []string{"head"}, second: dataOptions, allowedWithGet: true},
+	{first: []string{"upload-file"}, second: append([]string{"form", "form-string"}, dataOptions...)},
+}
+
+// headerOptions 会生成请求头的选项，curl中 -H 指定的同名请求头优先
+var headerOptions = []struct {
+	option string
+	header string
+}{
+	{option: "user-agent", header: "user-agent"},
+	{option: "referer", header: "referer"},
+	{option: "user", header: "authorization"},
+}
+
+// diagnostic 构造指向该参数的诊断信息
+func (a curlArg) diagnostic(severity Severity, code ErrorCode, format string, v ...any) Diagnostic {
+	return Diagnostic{
+		Severity: severity,
+		Code:     code,
+		Option:   a.flag,
+		Index:    a.index,
+		Offset:   a.offset,
+		Message:  fmt.Sprintf(format, v...),
+	}
+}
+
+// diagnose 检查被忽略、互相冲突、被覆盖以及可疑的内容，结果追加到 req.Warnings
+// 这些问题不影响解析结果，严格模式下也不会导致解析失败。
+func (cp *CurlParser) diagnose(args []curlArg, req *HTTPRequest) {
+	headers := make(map[string]curlArg)
+	url, _ := urlArg(args)
+	for _, arg := range args {
+		switch {
+		case arg.is("", "url"):
+			switch {
+			case arg.valueIndex == url.valueIndex:
+				if !strings.Contains(arg.value, "://") {
+					req.Warnings = append(req.Warnings, arg.diagnostic(SeverityInfo, CodeSuspiciousInput, "URL缺少协议，curl会默认使用 http://"))
+				}
+			case arg.unknownBefore != "":
+				req.Warnings = append(req.Warnings, arg.diagnostic(SeverityInfo, CodeIgnoredOption, "%q 可能是未知选项 %s 的参数，已忽略", arg.value, arg.unknownBefore))
+			default:
+				req.Warnings = append(req.Warnings, arg.diagnostic(SeverityInfo, CodeIgnoredOption, "只解析第一个URL，%q 被忽略", arg.value))
+			}
+
+		case longOptions[arg.name].lossy && !arg.negated:
+			// 只保留选项和参数，位置与请求内容无关
+			req.unsupported = append(req.unsupported, curlArg{name: arg.name, value: arg.value})
+			req.Warnings = append(req.Warnings, arg.diagnostic(SeverityWarning, CodeUnsupportedOption, "选项会改变curl发送的请求，但解析结果中没有对应的内容，ToHTTPRequest 和 Execute 发送的请求与curl不同"))
+
+		case !longOptions[arg.name].supported:
+			req.Warnings = append(req.Warnings, arg.diagnostic(SeverityInfo, CodeIgnoredOption, "选项不影响请求内容，已忽略"))
+
+		case arg.name == "header":
+			header, remove, ok := parseHeaderLine(arg.value)
+			if !ok {
+				req.Warnings = append(req.Warnings, arg.diagnostic(SeverityWarning, CodeSuspiciousInput, "请求头 %q 格式无效，curl会忽略它", arg.value))
+				continue
+			}
+			key := strings.ToLower(header.Name)
+			if _, exists := headers[key]; exists && !remove {
+				// curl会发送所有同名请求头，只有 Headers 中之前的值被覆盖
+				req.Warnings = append(req.Warnings, arg.diagnostic(SeverityInfo, CodeOverwrittenHeader, "同名请求头 %s 出现多次，Headers 中只保留最后一个，完整内容见 HeaderList", header.Name))
+			}
+			headers[key] = arg
+		}
+	}
+
+	// -A、-e、-u 生成的请求头会被 -H 指定的同名请求头覆盖
+	for _, h := range headerOptions {
+		header, ok := headers[h.header]
+		if !ok {
+			continue
+		}
+		if option, found := firstArg(args, h.option); found {
+			req.Warnings = append(req.Warnings, header.diagnostic(SeverityWarning, CodeOverwrittenHeader, "覆盖了 %s 设置的请求头", option.flag))
+		}
+	}
+
+	for _, c := range conflictingOptions {
+		first, ok := firstArg(args, c.first...)
+		if !ok || c.allowedWithGet && flagEnabled(args, "get") {
+			continue
+		}
+		if second, found := firstArg(args, c.second...); found {
+			req.Warnings = append(req.Warnings, second.diagnostic(SeverityWarning, CodeConflictingOptions, "不能与 %s 同时使用", first.flag))
+		}
+	}
+
+	// 使用 -X GET 或 -X HEAD 时仍然带有请求体，通常是漏写了 -G
+	if (req.Method == "GET" || req.Method == "HEAD") && req.Body != "" {
+		if method, ok := firstArg(args, "request"); ok {
+			req.Warnings = append(req.Warnings, method.diagnostic(SeverityWarning, CodeSuspiciousInput, "%s 请求带有请求体，是否需要使用 -G 把数据放到URL中", req.Method))
+		}
+	}
+}
+
+// firstArg 返回第一次出现的指定选项
+func firstArg(args []curlArg, names ...string) (curlArg, bool) {
+	for _, arg := range args {
+		if arg.is(names...) {
+			return arg, true
+		}
+	}
+	return curlArg{}, false
+}
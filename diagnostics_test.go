@@ -0,0 +1,126 @@
+package curl_parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCurlParser_Diagnostics(t *testing.T) {
+	type diag struct {
+		Severity Severity
+		Code     ErrorCode
+		Option   string
+	}
+	tests := []struct {
+		name        string
+		curlCommand string
+		want        []diag
+	}{
+		{
+			name:        "No diagnostics",
+			curlCommand: `curl -H 'Accept: a' -d x https://a.com`,
+			want:        nil,
+		},
+		{
+			name:        "Ignored output options",
+			curlCommand: `curl -o out.json --trace trace.log -sS https://a.com`,
+			want: []diag{
+				{SeverityInfo, CodeIgnoredOption, "-o"},
+				{SeverityInfo, CodeIgnoredOption, "--trace"},
+				{SeverityInfo, CodeIgnoredOption, "-s"},
+				{SeverityInfo, CodeIgnoredOption, "-S"},
+			},
+		},
+		{
+			name:        "Options that change the request",
+			curlCommand: `curl --oauth2-bearer tok --url-query a=b --compressed --digest -U p:q -s --no-path-as-is https://a.com`,
+			want: []diag{
+				{SeverityWarning, CodeUnsupportedOption, "--oauth2-bearer"},
+				{SeverityWarning, CodeUnsupportedOption, "--url-query"},
+				{SeverityWarning, CodeUnsupportedOption, "--compressed"},
+				{SeverityWarning, CodeUnsupportedOption, "--digest"},
+				{SeverityWarning, CodeUnsupportedOption, "-U"},
+				{SeverityInfo, CodeIgnoredOption, "-s"},
+				{SeverityInfo, CodeIgnoredOption, "--no-path-as-is"},
+			},
+		},
+		{
+			name:        "Extra URLs and missing scheme",
+			curlCommand: `curl example.com https://b.com`,
+			want: []diag{
+				{SeverityInfo, CodeSuspiciousInput, ""},
+				{SeverityInfo, CodeIgnoredOption, ""},
+			},
+		},
+		{
+			name:        "Get with form",
+			curlCommand: `curl -G -F a=b https://a.com`,
+			want: []diag{
+				{SeverityWarning, CodeConflictingOptions, "-F"},
+			},
+		},
+		{
+			name:        "Form with data",
+			curlCommand: `curl -F a=b --data-raw c https://a.com`,
+			want: []diag{
+				{SeverityWarning, CodeConflictingOptions, "--data-raw"},
+			},
+		},
+		{
+			name:        "Overwritten headers",
+			curlCommand: `curl -A agent -u a:b -H 'Accept: a' -H 'accept: b' -H 'User-Agent: x' -H 'Authorization: Bearer t' https://a.com`,
+			want: []diag{
+				{SeverityInfo, CodeOverwrittenHeader, "-H"},
+				{SeverityWarning, CodeOverwrittenHeader, "-H"},
+				{SeverityWarning, CodeOverwrittenHeader, "-H"},
+			},
+		},
+		{
+			name:        "Header without colon",
+			curlCommand: `curl -H 'X-Broken' https://a.com`,
+			want: []diag{
+				{SeverityWarning, CodeSuspiciousInput, "-H"},
+			},
+		},
+		{
+			name:        "Body on GET",
+			curlCommand: `curl -X GET -d 'q=1' https://a.com`,
+			want: []diag{
+				{SeverityWarning, CodeSuspiciousInput, "-X"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := NewCurlParser(tt.curlCommand).Parse()
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			var got []diag
+			for _, w := range req.Warnings {
+				if w.Message == "" {
+					t.Errorf("diagnostic without message: %+v", w)
+				}
+				got = append(got, diag{w.Severity, w.Code, w.Option})
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Warnings = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCurlParser_DiagnosticPosition(t *testing.T) {
+	cmd := `curl https://a.com -H 'A: 1' -H 'a: 2'`
+	req, err := NewCurlParser(cmd).Parse()
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(req.Warnings) != 1 {
+		t.Fatalf("Warnings = %v, want 1 entry", req.Warnings)
+	}
+	if w := req.Warnings[0]; w.Index != 4 || w.Offset != 29 {
+		t.Errorf("Warnings[0] index = %d offset = %d, want 4 and 29", w.Index, w.Offset)
+	}
+}
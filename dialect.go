@@ -0,0 +1,150 @@
+package curl_parser
+
+import "strings"
+
+// Dialect 表示curl命令所使用的shell引号规则
+type Dialect int
+
+const (
+	// DialectAuto 根据命令内容自动识别方言
+	DialectAuto Dialect = iota
+	// DialectBash bash、zsh等POSIX shell，对应浏览器的 "Copy as cURL (bash)"
+	DialectBash
+	// DialectCmd Windows cmd.exe，对应浏览器的 "Copy as cURL (cmd)"
+	DialectCmd
+	// DialectPowerShell Windows PowerShell，通常以 curl.exe 调用并用反引号续行
+	DialectPowerShell
+)
+
+// String 返回方言名称
+func (d Dialect) String() string {
+	switch d {
+	case DialectBash:
+		return "bash"
+	case DialectCmd:
+		return "cmd"
+	case DialectPowerShell:
+		return "powershell"
+	default:
+		return "auto"
+	}
+}
+
+// detectDialect 根据命令内容猜测方言
+func detectDialect(cmd string) Dialect {
+	// cmd.exe 使用 ^ 作为转义符和续行符，浏览器生成的命令中每个参数都以 ^" 开头
+	if hasUnquotedCaret(cmd) {
+		return DialectCmd
+	}
+	lines := strings.Split(cmd, "\n")
+
+	// PowerShell 使用反引号续行，并且通常显式调用 curl.exe 以避开 Invoke-WebRequest 别名
+	for _, line := range lines {
+		if strings.HasSuffix(strings.TrimRight(line, "\r"), "`") {
+			return DialectPowerShell
+		}
+	}
+	if name := programName(cmd); strings.EqualFold(name[strings.LastIndexAny(name, `/\`)+1:], "curl.exe") {
+		return DialectPowerShell
+	}
+
+	return DialectBash
+}
+
+// hasUnquotedCaret 判断命令中是否有bash引号之外、位于参数开头的 ^" 或位于行尾的续行符 ^
+// bash单引号、双引号和 $'...' 中的 ^ 是普通字符，例如 -d 'pattern= ^"abc'。
+func hasUnquotedCaret(cmd string) bool {
+	var quote byte
+	for i := 0; i < len(cmd); i++ {
+		c := cmd[i]
+		switch {
+		case quote == '\'':
+			if c == '\'' {
+				quote = 0
+			}
+		case quote != 0:
+			// 双引号和 $'' 中的 \ 转义下一个字符
+			if c == '\\' {
+				i++
+			} else if c == '"' && quote == '"' || c == '\'' && quote == '$' {
+				quote = 0
+			}
+		case c == '\\':
+			i++
+		case c == '\'':
+			quote = '\''
+			if i > 0 && cmd[i-1] == '$' {
+				quote = '$'
+			}
+		case c == '"':
+			quote = '"'
+		case c == '^' && i > 0 && (cmd[i-1] == ' ' || cmd[i-1] == '\t'):
+			rest := strings.TrimPrefix(cmd[i+1:], "\r")
+			if strings.HasPrefix(rest, `"`) || rest == "" || rest[0] == '\n' {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// programName 返回命令中的程序名，去掉PowerShell的调用运算符 & 和程序名两边的引号
+func programName(cmd string) string {
+	cmd = strings.TrimSpace(cmd)
+	if rest, ok := strings.CutPrefix(cmd, "&"); ok {
+		cmd = strings.TrimSpace(rest)
+	}
+	if cmd != "" && (cmd[0] == '\'' || cmd[0] == '"') {
+		if end := strings.IndexByte(cmd[1:], cmd[0]); end >= 0 {
+			return cmd[1 : end+1]
+		}
+	}
+	if end := strings.IndexAny(cmd, " \t\r\n"); end >= 0 {
+		return cmd[:end]
+	}
+	return cmd
+}
+
+// tokenize 按照指定方言把命令切分为argv，dialect不能为DialectAuto
+func tokenize(cmd string, dialect Dialect) ([]token, error) {
+	switch dialect {
+	case DialectCmd:
+		return tokenizeCmd(cmd)
+	case DialectPowerShell:
+		return tokenizePowerShell(cmd)
+	default:
+		return tokenizeBash(cmd)
+	}
+}
+
+// skipProgramName 跳过argv开头的curl程序名，返回第一个curl参数的下标
+// bash中程序名区分大小写，可以带路径，例如 /usr/bin/curl；
+// Windows中不区分大小写，可以带 .exe 后缀和反斜杠路径；
+// PowerShell中还可能以调用运算符 & 开头，例如 & 'C:\curl\curl.exe'。
+func skipProgramName(tokens []token, dialect Dialect) int {
+	first := 0
+	if dialect == DialectPowerShell && len(tokens) > 0 && tokens[0].value == "&" {
+		first = 1
+	}
+	if first >= len(tokens) {
+		return first
+	}
+
+	name := tokens[first].value
+	if dialect == DialectBash {
+		name = name[strings.LastIndexByte(name, '/')+1:]
+		if name == "curl" {
+			return first + 1
+		}
+		return first
+	}
+
+	if dialect == DialectPowerShell {
+		name = strings.TrimPrefix(name, "&")
+	}
+	name = strings.ToLower(name[strings.LastIndexAny(name, `/\`)+1:])
+	if name == "curl" || name == "curl.exe" {
+		return first + 1
+	}
+	return first
+}
@@ -0,0 +1,219 @@
+package curl_parser
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// chromeEscapeWin 按照Chrome "Copy as cURL (cmd)" 的规则转义参数
+func chromeEscapeWin(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = regexp.MustCompile(`[^a-zA-Z0-9\s_\-:=+~'/.,?;()*`+"`"+`]`).ReplaceAllString(s, "^$0")
+	s = regexp.MustCompile(`%([a-zA-Z0-9_])`).ReplaceAllString(s, "%^$1")
+	s = regexp.MustCompile(`\r?\n`).ReplaceAllString(s, "^\n\n")
+	return `^"` + s + `^"`
+}
+
+func TestDetectDialect(t *testing.T) {
+	tests := []struct {
+		name string
+		cmd  string
+		want Dialect
+	}{
+		{
+			name: "Bash",
+			cmd:  "curl 'https://a.com' \\\n  -H 'a: b'",
+			want: DialectBash,
+		},
+		{
+			name: "Caret quoted cmd",
+			cmd:  `curl ^"https://a.com^"`,
+			want: DialectCmd,
+		},
+		{
+			name: "Caret continuation",
+			cmd:  "curl https://a.com ^\r\n  -H \"a: b\"",
+			want: DialectCmd,
+		},
+		{
+			name: "PowerShell backtick continuation",
+			cmd:  "curl https://a.com `\n  -H 'a: b'",
+			want: DialectPowerShell,
+		},
+		{
+			name: "PowerShell curl.exe",
+			cmd:  `curl.exe -d 'it''s' https://a.com`,
+			want: DialectPowerShell,
+		},
+		{
+			name: "PowerShell call operator",
+			cmd:  `& curl.exe https://a.com`,
+			want: DialectPowerShell,
+		},
+		{
+			name: "PowerShell quoted program path",
+			cmd:  `& 'C:\curl\curl.exe' https://a.com`,
+			want: DialectPowerShell,
+		},
+		{
+			name: "Windows program path",
+			cmd:  `C:\curl\CURL.EXE https://a.com`,
+			want: DialectPowerShell,
+		},
+		{
+			name: "Bash curl path",
+			cmd:  `/usr/bin/curl https://a.com`,
+			want: DialectBash,
+		},
+		{
+			name: "Caret inside bash single quotes",
+			cmd:  `curl -d 'a^"b' https://a.com`,
+			want: DialectBash,
+		},
+		{
+			name: "Caret after space inside bash quotes",
+			cmd:  `curl -d 'pattern= ^"abc' -H "X-A: b ^" https://a.com`,
+			want: DialectBash,
+		},
+		{
+			name: "Caret continuation inside bash quotes",
+			cmd:  "curl -d 'a ^\nb' https://a.com",
+			want: DialectBash,
+		},
+		{
+			name: "Caret quoted cmd after double quotes",
+			cmd:  `curl "https://a.com" -H ^"a: b^"`,
+			want: DialectCmd,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectDialect(tt.cmd); got != tt.want {
+				t.Errorf("detectDialect() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCurlParser_CmdMatchesBash(t *testing.T) {
+	url := "https://httpbin.org/post?a=1&b=%41"
+	headers := []string{"content-type: application/json", "x-pct: 100% done", "cookie: a=1; b=2"}
+	body := "{\"msg\":\"say \\\"hi\\\"\",\"path\":\"C:\\\\tmp\",\"text\":\"line1\nline2\",\"env\":\"%PATH%\"}"
+
+	bashCmd := "curl '" + url + "' \\\n"
+	cmdCmd := "curl " + chromeEscapeWin(url) + " ^\n"
+	for _, h := range headers {
+		bashCmd += "  -H '" + h + "' \\\n"
+		cmdCmd += "  -H " + chromeEscapeWin(h) + " ^\n"
+	}
+	bashCmd += "  --data-raw $'" + strings.NewReplacer(`\`, `\\`, "'", `\'`, "\n", `\n`).Replace(body) + "' \\\n  --compressed"
+	cmdCmd += "  --data-raw " + chromeEscapeWin(body) + " ^\n  --compressed"
+
+	want, err := NewCurlParser(bashCmd).Parse()
+	if err != nil {
+		t.Fatalf("Parse(bash) error = %v", err)
+	}
+	if want.Body != body {
+		t.Fatalf("Parse(bash) Body = %q, want %q", want.Body, body)
+	}
+
+	for _, dialect := range []Dialect{DialectAuto, DialectCmd} {
+		got, err := NewCurlParser(cmdCmd, WithDialect(dialect)).Parse()
+		if err != nil {
+			t.Fatalf("Parse(cmd, %v) error = %v", dialect, err)
+		}
+		assertSameRequest(t, got, want)
+	}
+}
+
+func TestSkipProgramName(t *testing.T) {
+	tests := []struct {
+		name    string
+		argv    []string
+		dialect Dialect
+		want    int
+	}{
+		{name: "Bash curl", argv: []string{"curl", "-L"}, dialect: DialectBash, want: 1},
+		{name: "Bash path", argv: []string{"/usr/bin/curl", "-L"}, dialect: DialectBash, want: 1},
+		{name: "Bash is case sensitive", argv: []string{"CURL", "-L"}, dialect: DialectBash, want: 0},
+		{name: "Bash without program", argv: []string{"-L", "https://a.com"}, dialect: DialectBash, want: 0},
+		{name: "Cmd curl.exe", argv: []string{"CURL.EXE", "-L"}, dialect: DialectCmd, want: 1},
+		{name: "Cmd path", argv: []string{`C:\Windows\System32\curl.exe`, "-L"}, dialect: DialectCmd, want: 1},
+		{name: "PowerShell call operator", argv: []string{"&", `C:\curl\curl.exe`, "-L"}, dialect: DialectPowerShell, want: 2},
+		{name: "PowerShell attached call operator", argv: []string{"&curl.exe", "-L"}, dialect: DialectPowerShell, want: 1},
+		{name: "PowerShell curl", argv: []string{"curl", "-L"}, dialect: DialectPowerShell, want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens := make([]token, 0, len(tt.argv))
+			for _, v := range tt.argv {
+				tokens = append(tokens, token{value: v})
+			}
+			if got := skipProgramName(tokens, tt.dialect); got != tt.want {
+				t.Errorf("skipProgramName() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCurlParser_PowerShellMatchesBash(t *testing.T) {
+	bashCmd := `curl -X PUT -H 'X-Quote: it'"'"'s' -H "X-Path: C:\\tmp" -d '{"a":"b"}' https://httpbin.org/put`
+	psCmd := "& curl.exe -X PUT `\n  -H 'X-Quote: it''s' `\n  -H \"X-Path: C:\\tmp\" `\n  -d '{\"a\":\"b\"}' `\n  https://httpbin.org/put"
+
+	want, err := NewCurlParser(bashCmd).Parse()
+	if err != nil {
+		t.Fatalf("Parse(bash) error = %v", err)
+	}
+	if want.Headers["X-Quote"] != "it's" || want.Headers["X-Path"] != `C:\tmp` {
+		t.Fatalf("Parse(bash) Headers = %v", want.Headers)
+	}
+
+	for _, dialect := range []Dialect{DialectAuto, DialectPowerShell} {
+		got, err := NewCurlParser(psCmd, WithDialect(dialect)).Parse()
+		if err != nil {
+			t.Fatalf("Parse(powershell, %v) error = %v", dialect, err)
+		}
+		assertSameRequest(t, got, want)
+	}
+}
+
+func TestCurlParser_WindowsProgramPath(t *testing.T) {
+	for _, cmd := range []string{
+		`& 'C:\curl\curl.exe' -H 'a: b' https://a.com`,
+		`& "C:\Program Files\curl\curl.exe" -H 'a: b' https://a.com`,
+		`C:\curl\curl.exe -H "a: b" https://a.com`,
+	} {
+		req, err := NewCurlParser(cmd, WithDialect(DialectAuto)).Parse()
+		if err != nil {
+			t.Fatalf("Parse(%s) error = %v", cmd, err)
+		}
+		if req.URL != "https://a.com" || req.Headers["a"] != "b" {
+			t.Errorf("Parse(%s) URL = %s, Headers = %v", cmd, req.URL, req.Headers)
+		}
+	}
+}
+
+// assertSameRequest 比较不同方言解析出的请求，警告的位置信息因方言而异，只比较类型和选项
+func assertSameRequest(t *testing.T, got, want *HTTPRequest) {
+	t.Helper()
+
+	if len(got.Warnings) != len(want.Warnings) {
+		t.Fatalf("Warnings = %v, want %v", got.Warnings, want.Warnings)
+	}
+	for i := range got.Warnings {
+		if got.Warnings[i].Code != want.Warnings[i].Code || got.Warnings[i].Option != want.Warnings[i].Option {
+			t.Errorf("Warnings[%d] = %v, want %v", i, got.Warnings[i], want.Warnings[i])
+		}
+	}
+
+	g, w := *got, *want
+	g.Warnings, w.Warnings = nil, nil
+	if !reflect.DeepEqual(g, w) {
+		t.Errorf("Parse() = %+v, want %+v", g, w)
+	}
+}
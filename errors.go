@@ -0,0 +1,122 @@
+package curl_parser
+
+import "fmt"
+
+// ErrorCode 解析错误的类型，供程序判断使用
+type ErrorCode string
+
+const (
+	// CodeUnterminatedQuote 引号未闭合
+	CodeUnterminatedQuote ErrorCode = "unterminated_quote"
+	// CodeMissingArgument 选项缺少参数
+	CodeMissingArgument ErrorCode = "missing_argument"
+	// CodeInvalidNumber 选项的参数不是合法的数字
+	CodeInvalidNumber ErrorCode = "invalid_number"
+	// CodeUnknownOption 未知的选项
+	CodeUnknownOption ErrorCode = "unknown_option"
+	// CodeDuplicateOption 只能出现一次的选项出现了多次
+	CodeDuplicateOption ErrorCode = "duplicate_option"
+	// CodeNoURL 命令中没有URL
+	CodeNoURL ErrorCode = "no_url"
+	// CodeIgnoredOption 选项能够识别，但解析结果中没有对应的字段，并且不影响发送的请求
+	CodeIgnoredOption ErrorCode = "ignored_option"
+	// CodeUnsupportedOption 选项会改变curl发送的请求，但解析结果中没有对应的字段，例如 --oauth2-bearer
+	CodeUnsupportedOption ErrorCode = "unsupported_option"
+	// CodeConflictingOptions 选项之间互相冲突
+	CodeConflictingOptions ErrorCode = "conflicting_options"
+	// CodeOverwrittenHeader 请求头被后面的选项覆盖
+	CodeOverwrittenHeader ErrorCode = "overwritten_header"
+	// CodeSuspiciousInput 命令可以解析，但很可能不是用户想要的结果
+	CodeSuspiciousInput ErrorCode = "suspicious_input"
+	// CodeFileRead @file 引用的文件不存在或无法读取
+	CodeFileRead ErrorCode = "file_read"
+	// CodeInvalidForm -F 的参数格式不正确
+	CodeInvalidForm ErrorCode = "invalid_form"
+)
+
+// Severity 诊断信息的严重程度
+type Severity int
+
+const (
+	// SeverityInfo 提示信息，例如被忽略的输出类选项
+	SeverityInfo Severity = iota
+	// SeverityWarning 警告，解析结果可能与用户的预期或curl实际发送的请求不一致
+	SeverityWarning
+)
+
+// String 返回严重程度的名称
+func (s Severity) String() string {
+	if s == SeverityWarning {
+		return "warning"
+	}
+	return "info"
+}
+
+// ParseError 解析curl命令失败时返回的错误，可以通过 errors.As 获取
+type ParseError struct {
+	// 错误类型
+	Code ErrorCode
+	// 出错的选项，保持命令中的写法，例如 "-H" 或 "--header"；与选项无关时为空
+	Option string
+	// 出错的参数在argv中的下标（包含开头的curl），无法定位时为-1
+	Index int
+	// 出错位置在原始命令中的字节偏移，无法定位时为-1
+	Offset int
+	// 错误描述
+	Message string
+}
+
+// Error 实现error接口
+func (e *ParseError) Error() string {
+	msg := e.Message
+	if e.Option != "" {
+		msg = fmt.Sprintf("%s: %s", e.Option, msg)
+	}
+	if e.Offset >= 0 {
+		msg = fmt.Sprintf("%s (位置 %d)", msg, e.Offset)
+	}
+	return "解析curl命令失败: " + msg
+}
+
+// diagnostic 把错误转换为警告
+func (e *ParseError) diagnostic() Diagnostic {
+	return Diagnostic{
+		Severity: SeverityWarning,
+		Code:     e.Code,
+		Option:   e.Option,
+		Index:    e.Index,
+		Offset:   e.Offset,
+		Message:  e.Message,
+	}
+}
+
+// Diagnostic 解析过程中发现的非致命问题，记录在 HTTPRequest.Warnings 中
+// 包括宽松模式下被跳过的未知选项、缺少参数和重复选项，被忽略的选项，互相冲突的选项，
+// 被覆盖的请求头以及可疑的输入。
+type Diagnostic struct {
+	// 严重程度
+	Severity Severity
+	// 问题类型
+	Code ErrorCode
+	// 相关的选项，保持命令中的写法；与选项无关时为空
+	Option string
+	// 相关参数在argv中的下标（包含开头的curl），无法定位时为-1
+	Index int
+	// 在原始命令中的字节偏移，无法定位时为-1
+	Offset int
+	// 问题描述
+	Message string
+}
+
+// String 返回可读的描述
+func (d Diagnostic) String() string {
+	msg := d.Message
+	if d.Option != "" {
+		msg = fmt.Sprintf("%s: %s", d.Option, msg)
+	}
+	msg = fmt.Sprintf("%s: %s", d.Severity, msg)
+	if d.Offset >= 0 {
+		msg = fmt.Sprintf("%s (位置 %d)", msg, d.Offset)
+	}
+	return msg
+}
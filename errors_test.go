@@ -0,0 +1,270 @@
+package curl_parser
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestCurlParser_ParseError(t *testing.T) {
+	tests := []struct {
+		name        string
+		curlCommand string
+		opts        []Option
+		want        ParseError
+	}{
+		{
+			name:        "Unterminated single quote",
+			curlCommand: `curl -H 'Accept: */* https://a.com`,
+			want:        ParseError{Code: CodeUnterminatedQuote, Index: 2, Offset: 8},
+		},
+		{
+			name:        "Unterminated double quote in the middle of a word",
+			curlCommand: `curl https://a.com -d a"bc`,
+			want:        ParseError{Code: CodeUnterminatedQuote, Index: 3, Offset: 23},
+		},
+		{
+			name:        "Unterminated quote in cmd",
+			curlCommand: `curl ^"https://a.com`,
+			opts:        []Option{WithDialect(DialectCmd)},
+			want:        ParseError{Code: CodeUnterminatedQuote, Index: 1, Offset: 6},
+		},
+		{
+			name:        "Missing argument for long option",
+			curlCommand: `curl https://a.com --header`,
+			opts:        []Option{WithStrict(true)},
+			want:        ParseError{Code: CodeMissingArgument, Option: "--header", Index: 2, Offset: 19},
+		},
+		{
+			name:        "Missing argument for short option in a group",
+			curlCommand: `curl https://a.com -sH`,
+			opts:        []Option{WithStrict(true)},
+			want:        ParseError{Code: CodeMissingArgument, Option: "-H", Index: 2, Offset: 19},
+		},
+		{
+			name:        "Invalid number",
+			curlCommand: `curl --connect-timeout 5 --max-time 'ten' https://a.com`,
+			want:        ParseError{Code: CodeInvalidNumber, Option: "--max-time", Index: 4, Offset: 36},
+		},
+		{
+			name:        "Invalid attached number",
+			curlCommand: `curl -m-1 https://a.com`,
+			want:        ParseError{Code: CodeInvalidNumber, Option: "-m", Index: 1, Offset: 7},
+		},
+		{
+			name:        "Invalid attached value points at the value",
+			curlCommand: `curl -mabc https://a.com`,
+			want:        ParseError{Code: CodeInvalidNumber, Option: "-m", Index: 1, Offset: 7},
+		},
+		{
+			name:        "Invalid attached value in a group",
+			curlCommand: `curl -sLmabc https://a.com`,
+			want:        ParseError{Code: CodeInvalidNumber, Option: "-m", Index: 1, Offset: 9},
+		},
+		{
+			name:        "Invalid attached quoted value",
+			curlCommand: `curl -m"a b" https://a.com`,
+			want:        ParseError{Code: CodeInvalidNumber, Option: "-m", Index: 1, Offset: 8},
+		},
+		{
+			name:        "Invalid attached value in cmd",
+			curlCommand: `curl ^"-m^%abc^" https://a.com`,
+			opts:        []Option{WithDialect(DialectCmd)},
+			want:        ParseError{Code: CodeInvalidNumber, Option: "-m", Index: 1, Offset: 10},
+		},
+		{
+			name:        "No URL",
+			curlCommand: `curl -H 'Accept: */*'`,
+			want:        ParseError{Code: CodeNoURL, Index: -1, Offset: -1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewCurlParser(tt.curlCommand, tt.opts...).Parse()
+			var perr *ParseError
+			if !errors.As(err, &perr) {
+				t.Fatalf("Parse() error = %v, want *ParseError", err)
+			}
+			if perr.Code != tt.want.Code || perr.Option != tt.want.Option ||
+				perr.Index != tt.want.Index || perr.Offset != tt.want.Offset {
+				t.Errorf("Parse() error = %+v, want %+v", *perr, tt.want)
+			}
+			if perr.Message == "" || perr.Error() == "" {
+				t.Errorf("Parse() error has no message: %+v", *perr)
+			}
+		})
+	}
+}
+
+func TestCurlParser_StrictMode(t *testing.T) {
+	tests := []struct {
+		name         string
+		curlCommand  string
+		wantCode     ErrorCode
+		wantOption   string
+		wantWarnings int
+	}{
+		{
+			name:         "Unknown long option",
+			curlCommand:  `curl --frobnicate https://a.com`,
+			wantCode:     CodeUnknownOption,
+			wantOption:   "--frobnicate",
+			wantWarnings: 1,
+		},
+		{
+			name:         "Unknown short option in a group",
+			curlCommand:  `curl -sQL https://a.com`,
+			wantCode:     CodeUnknownOption,
+			wantOption:   "-Q",
+			wantWarnings: 1,
+		},
+		{
+			name:         "Missing argument",
+			curlCommand:  `curl https://a.com -X`,
+			wantCode:     CodeMissingArgument,
+			wantOption:   "-X",
+			wantWarnings: 1,
+		},
+		{
+			name:         "Duplicate single-valued option",
+			curlCommand:  `curl -X POST --request PUT https://a.com`,
+			wantCode:     CodeDuplicateOption,
+			wantOption:   "--request",
+			wantWarnings: 1,
+		},
+		{
+			name:         "Repeatable options are not duplicates",
+			curlCommand:  `curl -H 'A: 1' -H 'B: 2' -d a -d b -sS -s https://a.com`,
+			wantWarnings: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewCurlParser(tt.curlCommand, WithStrict(true)).Parse()
+			if tt.wantCode == "" {
+				if err != nil {
+					t.Fatalf("Parse(strict) error = %v", err)
+				}
+			} else {
+				var perr *ParseError
+				if !errors.As(err, &perr) {
+					t.Fatalf("Parse(strict) error = %v, want *ParseError", err)
+				}
+				if perr.Code != tt.wantCode || perr.Option != tt.wantOption {
+					t.Errorf("Parse(strict) error = %+v, want code %s option %s", *perr, tt.wantCode, tt.wantOption)
+				}
+			}
+
+			req, err := NewCurlParser(tt.curlCommand).Parse()
+			if err != nil {
+				t.Fatalf("Parse(lenient) error = %v", err)
+			}
+			var warnings []Diagnostic
+			for _, w := range req.Warnings {
+				if w.Severity == SeverityWarning {
+					warnings = append(warnings, w)
+				}
+			}
+			if len(warnings) != tt.wantWarnings {
+				t.Fatalf("Parse(lenient) Warnings = %v, want %d", req.Warnings, tt.wantWarnings)
+			}
+			if tt.wantWarnings > 0 {
+				w := warnings[0]
+				if w.Code != tt.wantCode || w.Option != tt.wantOption {
+					t.Errorf("Parse(lenient) Warnings[0] = %+v, want code %s option %s", w, tt.wantCode, tt.wantOption)
+				}
+			}
+		})
+	}
+}
+
+func TestCurlParser_LenientKeepsLastValue(t *testing.T) {
+	req, err := NewCurlParser(`curl -X POST --frobnicate -X put https://a.com -H`).Parse()
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if req.Method != "PUT" {
+		t.Errorf("Method = %v, want PUT", req.Method)
+	}
+	if req.URL != "https://a.com" {
+		t.Errorf("URL = %v, want https://a.com", req.URL)
+	}
+	if len(req.Warnings) != 3 {
+		t.Errorf("Warnings = %v, want 3 entries", req.Warnings)
+	}
+}
+
+func TestCurlParser_UnknownOptionArgument(t *testing.T) {
+	tests := []struct {
+		name        string
+		curlCommand string
+		wantURL     string
+		wantBody    string
+		wantCodes   []ErrorCode
+	}{
+		{
+			name:        "Known option with argument",
+			curlCommand: `curl --proxy-header "X: y" --etag-save e.txt --doh-url https://doh.example https://a.com`,
+			wantURL:     "https://a.com",
+			wantCodes:   []ErrorCode{CodeUnsupportedOption, CodeIgnoredOption, CodeIgnoredOption},
+		},
+		{
+			name:        "Unknown long option with argument",
+			curlCommand: `curl --frobnicate "X: y" https://a.com`,
+			wantURL:     "https://a.com",
+			wantCodes:   []ErrorCode{CodeUnknownOption, CodeIgnoredOption},
+		},
+		{
+			name:        "Unknown short option with argument",
+			curlCommand: `curl -Q value https://a.com`,
+			wantURL:     "https://a.com",
+			wantCodes:   []ErrorCode{CodeUnknownOption, CodeIgnoredOption},
+		},
+		{
+			name:        "URL right after unknown option",
+			curlCommand: `curl --frobnicate https://a.com`,
+			wantURL:     "https://a.com",
+			wantCodes:   []ErrorCode{CodeUnknownOption},
+		},
+		{
+			name:        "Only candidate after unknown option",
+			curlCommand: `curl --frobnicate a.com`,
+			wantURL:     "a.com",
+			wantCodes:   []ErrorCode{CodeUnknownOption, CodeSuspiciousInput},
+		},
+		{
+			name:        "End of options",
+			curlCommand: `curl -d x -- -d`,
+			wantURL:     "-d",
+			wantBody:    "x",
+			wantCodes:   []ErrorCode{CodeSuspiciousInput},
+		},
+		{
+			name:        "Options after end of options",
+			curlCommand: `curl -- https://a.com -H 'A: b'`,
+			wantURL:     "https://a.com",
+			wantCodes:   []ErrorCode{CodeIgnoredOption, CodeIgnoredOption},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := NewCurlParser(tt.curlCommand).Parse()
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if req.URL != tt.wantURL || req.Body != tt.wantBody {
+				t.Errorf("Parse() URL = %q, Body = %q, want %q, %q", req.URL, req.Body, tt.wantURL, tt.wantBody)
+			}
+			var codes []ErrorCode
+			for _, w := range req.Warnings {
+				codes = append(codes, w.Code)
+			}
+			if !reflect.DeepEqual(codes, tt.wantCodes) {
+				t.Errorf("Parse() Warnings = %v, want codes %v", req.Warnings, tt.wantCodes)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package curl_parser
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// Result 执行请求的结果
+type Result struct {
+	// 最终响应的状态码，例如 200
+	StatusCode int
+	// 最终响应的协议，例如 HTTP/1.1
+	Proto  string
+	Header http.Header
+	Body   []byte
+	// 最终请求的URL，跟随重定向后与 HTTPRequest.URL 不同
+	URL string
+	// 跟随的重定向，按顺序排列
+	Redirects []Redirect
+	Timing    Timing
+	// 使用 -f/--fail 且状态码大于等于400，curl会以退出码22失败
+	Failed bool
+}
+
+// Redirect 一次重定向
+type Redirect struct {
+	// 返回重定向的请求URL
+	URL string
+	// 重定向的状态码，例如 301、302
+	StatusCode int
+	// 重定向的目标地址
+	Location string
+}
+
+// Timing 请求各阶段的耗时，与curl -w 中的同名变量含义相同：
+// 都是从开始执行到该阶段结束的时间，跟随重定向时记录的是最后一个请求的各阶段。
+// 复用连接时没有DNS、连接和TLS阶段，对应的值为0。
+type Timing struct {
+	// time_namelookup 域名解析完成
+	NameLookup time.Duration
+	// time_connect TCP连接建立
+	Connect time.Duration
+	// time_appconnect TLS握手完成
+	AppConnect time.Duration
+	// time_starttransfer 收到响应的第一个字节
+	StartTransfer time.Duration
+	// time_redirect 所有重定向花费的时间
+	Redirect time.Duration
+	// time_total 请求完成
+	Total time.Duration
+}
+
+// Execute 发送请求并返回与curl行为一致的结果
+// 请求通过 ToHTTPRequest 生成，使用 NewHTTPClient 创建的客户端发送。
+// 与curl不同，--fail 时仍然会返回响应内容，是否失败见 Result.Failed。
+func (r *HTTPRequest) Execute(ctx context.Context) (*Result, error) {
+	client, err := NewHTTPClient(r)
+	if err != nil {
+		return nil, err
+	}
+	// 每次执行都会创建新的Transport，结束后关闭空闲连接，避免连接和goroutine泄漏
+	defer client.CloseIdleConnections()
+
+	var (
+		mu     sync.Mutex
+		start  = time.Now()
+		result = &Result{}
+	)
+	since := func(d *time.Duration) {
+		mu.Lock()
+		*d = time.Since(start)
+		mu.Unlock()
+	}
+	trace := &httptrace.ClientTrace{
+		DNSDone:              func(httptrace.DNSDoneInfo) { since(&result.Timing.NameLookup) },
+		ConnectDone:          func(string, string, error) { since(&result.Timing.Connect) },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { since(&result.Timing.AppConnect) },
+		GotFirstResponseByte: func() { since(&result.Timing.StartTransfer) },
+	}
+
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if err := r.checkRedirect(req, via); err != nil {
+			return err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		result.Redirects = append(result.Redirects, Redirect{
+			URL:        via[len(via)-1].URL.String(),
+			StatusCode: req.Response.StatusCode,
+			Location:   req.URL.String(),
+		})
+		result.Timing.Redirect = time.Since(start)
+		// 新的请求重新记录各阶段
+		result.Timing.NameLookup, result.Timing.Connect, result.Timing.AppConnect = 0, 0, 0
+		return nil
+	}
+
+	httpReq, err := r.ToHTTPRequest(httptrace.WithClientTrace(ctx, trace))
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	result.Timing.Total = time.Since(start)
+	result.StatusCode = resp.StatusCode
+	result.Proto = resp.Proto
+	result.Header = resp.Header
+	result.Body = body
+	result.URL = resp.Request.URL.String()
+	result.Failed = r.FailOnError && resp.StatusCode >= 400
+	return result, nil
+}
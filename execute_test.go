@@ -0,0 +1,205 @@
+package curl_parser
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestHTTPRequest_Execute(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/old":
+			w.Header().Set("Location", "/new")
+			w.WriteHeader(http.StatusMovedPermanently)
+			fmt.Fprint(w, "moved")
+		case "/new":
+			http.Redirect(w, r, server.URL+"/echo", http.StatusFound)
+		case "/echo":
+			body, _ := io.ReadAll(r.Body)
+			w.Header().Set("X-Method", r.Method)
+			w.Header().Set("X-Agent", r.Header.Get("User-Agent"))
+			fmt.Fprintf(w, "%s", body)
+		default:
+			http.Error(w, "missing", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name          string
+		cmd           string
+		wantStatus    int
+		wantBody      string
+		wantURL       string
+		wantRedirects []Redirect
+		wantFailed    bool
+		wantMethod    string
+	}{
+		{
+			name:       "Post body",
+			cmd:        `curl -d a=1 %s/echo`,
+			wantStatus: http.StatusOK,
+			wantBody:   "a=1",
+			wantURL:    "%s/echo",
+			wantMethod: "POST",
+		},
+		{
+			name:       "Redirect not followed",
+			cmd:        `curl %s/old`,
+			wantStatus: http.StatusMovedPermanently,
+			wantBody:   "moved",
+			wantURL:    "%s/old",
+		},
+		{
+			name:       "Redirect chain",
+			cmd:        `curl -L %s/old`,
+			wantStatus: http.StatusOK,
+			wantURL:    "%s/echo",
+			wantRedirects: []Redirect{
+				{URL: "%s/old", StatusCode: http.StatusMovedPermanently, Location: "%s/new"},
+				{URL: "%s/new", StatusCode: http.StatusFound, Location: "%s/echo"},
+			},
+			wantMethod: "GET",
+		},
+		{
+			name:       "Fail",
+			cmd:        `curl -f %s/missing`,
+			wantStatus: http.StatusNotFound,
+			wantBody:   "missing\n",
+			wantURL:    "%s/missing",
+			wantFailed: true,
+		},
+		{
+			name:       "Not found without fail",
+			cmd:        `curl %s/missing`,
+			wantStatus: http.StatusNotFound,
+			wantBody:   "missing\n",
+			wantURL:    "%s/missing",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := NewCurlParser(fmt.Sprintf(tt.cmd, server.URL)).Parse()
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			result, err := req.Execute(context.Background())
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+
+			if result.StatusCode != tt.wantStatus {
+				t.Errorf("StatusCode = %d, want %d", result.StatusCode, tt.wantStatus)
+			}
+			if string(result.Body) != tt.wantBody {
+				t.Errorf("Body = %q, want %q", result.Body, tt.wantBody)
+			}
+			if want := fmt.Sprintf(tt.wantURL, server.URL); result.URL != want {
+				t.Errorf("URL = %s, want %s", result.URL, want)
+			}
+			var wantRedirects []Redirect
+			for _, r := range tt.wantRedirects {
+				r.URL = fmt.Sprintf(r.URL, server.URL)
+				r.Location = fmt.Sprintf(r.Location, server.URL)
+				wantRedirects = append(wantRedirects, r)
+			}
+			if !reflect.DeepEqual(result.Redirects, wantRedirects) {
+				t.Errorf("Redirects = %+v, want %+v", result.Redirects, wantRedirects)
+			}
+			if result.Failed != tt.wantFailed {
+				t.Errorf("Failed = %v, want %v", result.Failed, tt.wantFailed)
+			}
+			if got := result.Header.Get("X-Method"); got != tt.wantMethod {
+				t.Errorf("X-Method = %s, want %s", got, tt.wantMethod)
+			}
+			if tt.wantMethod != "" && result.Header.Get("X-Agent") != DefaultUserAgent {
+				t.Errorf("X-Agent = %s, want %s", result.Header.Get("X-Agent"), DefaultUserAgent)
+			}
+			if result.Proto != "HTTP/1.1" {
+				t.Errorf("Proto = %s, want HTTP/1.1", result.Proto)
+			}
+		})
+	}
+}
+
+func TestHTTPRequest_ExecuteTiming(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/start" {
+			http.Redirect(w, r, "/end", http.StatusFound)
+		}
+	}))
+	defer server.Close()
+
+	req, err := NewCurlParser("curl -k -L " + server.URL + "/start").Parse()
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	result, err := req.Execute(context.Background())
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	timing := result.Timing
+	if timing.Redirect <= 0 || timing.StartTransfer < timing.Redirect || timing.Total < timing.StartTransfer {
+		t.Errorf("Timing = %+v, want increasing phases", timing)
+	}
+	if len(result.Redirects) != 1 {
+		t.Errorf("Redirects = %+v, want one redirect", result.Redirects)
+	}
+}
+
+func TestHTTPRequest_ExecuteFirstRequestTiming(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	req, err := NewCurlParser("curl -k " + server.URL).Parse()
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	result, err := req.Execute(context.Background())
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	timing := result.Timing
+	if timing.Connect <= 0 || timing.AppConnect < timing.Connect || timing.StartTransfer < timing.AppConnect || timing.Total < timing.StartTransfer {
+		t.Errorf("Timing = %+v, want increasing phases", timing)
+	}
+}
+
+func TestHTTPRequest_ExecuteReleasesConnections(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "ok")
+	}))
+	defer server.Close()
+
+	req, err := NewCurlParser("curl " + server.URL).Parse()
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	before := runtime.NumGoroutine()
+	for i := 0; i < 50; i++ {
+		if _, err := req.Execute(context.Background()); err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+	}
+
+	// 连接关闭是异步的，等待客户端和服务端的goroutine退出
+	deadline := time.Now().Add(5 * time.Second)
+	for runtime.NumGoroutine() > before+5 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if after := runtime.NumGoroutine(); after > before+5 {
+		t.Errorf("goroutines = %d after 50 Execute calls, want about %d", after, before)
+	}
+}
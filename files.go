@@ -0,0 +1,100 @@
+package curl_parser
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// FileReference 命令中引用但没有读取的文件，例如未配置文件系统时的 -H @headers.txt、未配置标准输入时的 -d @-
+type FileReference struct {
+	// 引用文件的选项，保持命令中的写法，例如 "-H"
+	Option string
+	// 命令中写的文件路径，"-" 表示标准输入
+	Path string
+	// 选项的参数在argv中的下标
+	Index int
+}
+
+// WithFS 指定读取 @file 引用的文件系统，例如 os.DirFS(".")
+// 不指定时文件引用保持原样，并记录在 HTTPRequest.UnresolvedFiles 中。
+func WithFS(fsys fs.FS) Option {
+	return func(cp *CurlParser) {
+		cp.fsys = fsys
+	}
+}
+
+// WithStdin 指定 @- 和 -T - 读取的标准输入
+// 标准输入只读取一次，多处引用时得到相同的内容；不指定时这些引用记录在 HTTPRequest.UnresolvedFiles 中。
+func WithStdin(r io.Reader) Option {
+	return func(cp *CurlParser) {
+		cp.stdin = r
+	}
+}
+
+// fsPath 把命令中的路径转换为 fs.FS 能接受的形式
+// fs.FS 只接受不以 "/" 开头的相对路径，"./a.txt" 和 "/etc/a.txt" 分别转换为 "a.txt" 和 "etc/a.txt"。
+func fsPath(name string) string {
+	return path.Clean(strings.TrimLeft(name, "/"))
+}
+
+// loadFile 读取参数引用的文件，"-" 表示标准输入
+// 没有配置文件系统或标准输入时记录为未解析的引用，ok返回false；文件不存在或读取失败时返回 *ParseError。
+func (cp *CurlParser) loadFile(req *HTTPRequest, arg curlArg, name string) (data []byte, ok bool, err error) {
+	if name == "-" && cp.stdin != nil {
+		if !cp.stdinRead {
+			cp.stdinData, err = io.ReadAll(cp.stdin)
+			if err != nil {
+				return nil, false, arg.errorf(CodeFileRead, "读取标准输入失败: %v", err)
+			}
+			cp.stdinRead = true
+		}
+		return cp.stdinData, true, nil
+	}
+
+	if cp.fsys == nil || name == "-" {
+		req.UnresolvedFiles = append(req.UnresolvedFiles, FileReference{Option: arg.flag, Path: name, Index: arg.valueIndex})
+		return nil, false, nil
+	}
+
+	data, err = fs.ReadFile(cp.fsys, fsPath(name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, false, arg.errorf(CodeFileRead, "文件 %s 不存在", name)
+	}
+	if err != nil {
+		return nil, false, arg.errorf(CodeFileRead, "读取文件 %s 失败: %v", name, err)
+	}
+	return data, true, nil
+}
+
+// expandHeaderFiles 将 -H @file 展开为文件中的每一行，每行一个请求头
+// 空行会被跳过；无法读取的引用从参数中移除，不会被当作请求头。
+func (cp *CurlParser) expandHeaderFiles(args []curlArg, req *HTTPRequest) ([]curlArg, error) {
+	expanded := make([]curlArg, 0, len(args))
+	for _, arg := range args {
+		if arg.name != "header" || !strings.HasPrefix(arg.value, "@") {
+			expanded = append(expanded, arg)
+			continue
+		}
+
+		data, ok, err := cp.loadFile(req, arg, arg.value[1:])
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			line = strings.TrimRight(line, "\r")
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			header := arg
+			header.value = line
+			expanded = append(expanded, header)
+		}
+	}
+	return expanded, nil
+}
@@ -0,0 +1,181 @@
+package curl_parser
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestCurlParser_HeaderFile(t *testing.T) {
+	fsys := fstest.MapFS{
+		"headers.txt":     {Data: []byte("X-A: 1\r\n\r\nAccept:\nX-Empty;\nX-A: 2\n")},
+		"dir/headers.txt": {Data: []byte("X-B: b")},
+	}
+
+	tests := []struct {
+		name           string
+		cmd            string
+		fsys           fstest.MapFS
+		wantList       HeaderList
+		wantRemoved    []string
+		wantUnresolved []FileReference
+		wantCode       ErrorCode
+	}{
+		{
+			name: "One header per line",
+			cmd:  `curl -H 'X-First: 0' -H @headers.txt -H 'X-Last: 3' https://a.com`,
+			fsys: fsys,
+			wantList: HeaderList{
+				{Name: "X-First", Value: "0"},
+				{Name: "X-A", Value: "1"},
+				{Name: "X-Empty"},
+				{Name: "X-A", Value: "2"},
+				{Name: "X-Last", Value: "3"},
+			},
+			wantRemoved: []string{"Accept"},
+		},
+		{
+			name:     "Relative and absolute paths",
+			cmd:      `curl -H @./dir/headers.txt -H @/dir/headers.txt https://a.com`,
+			fsys:     fsys,
+			wantList: HeaderList{{Name: "X-B", Value: "b"}, {Name: "X-B", Value: "b"}},
+		},
+		{
+			name:     "Missing file",
+			cmd:      `curl -H @missing.txt https://a.com`,
+			fsys:     fsys,
+			wantCode: CodeFileRead,
+		},
+		{
+			name:           "Unresolved without file system",
+			cmd:            `curl -H 'X-A: 1' --header @headers.txt https://a.com`,
+			wantList:       HeaderList{{Name: "X-A", Value: "1"}},
+			wantUnresolved: []FileReference{{Option: "--header", Path: "headers.txt", Index: 4}},
+		},
+		{
+			name:           "Stdin is unresolved",
+			cmd:            `curl -H @- https://a.com`,
+			fsys:           fsys,
+			wantUnresolved: []FileReference{{Option: "-H", Path: "-", Index: 2}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts []Option
+			if tt.fsys != nil {
+				opts = append(opts, WithFS(tt.fsys))
+			}
+			req, err := NewCurlParser(tt.cmd, opts...).Parse()
+			if tt.wantCode != "" {
+				var perr *ParseError
+				if !errors.As(err, &perr) || perr.Code != tt.wantCode {
+					t.Fatalf("Parse() error = %v, want code %s", err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if !reflect.DeepEqual(req.HeaderList, tt.wantList) {
+				t.Errorf("HeaderList = %v, want %v", req.HeaderList, tt.wantList)
+			}
+			if !reflect.DeepEqual(req.RemovedHeaders, tt.wantRemoved) {
+				t.Errorf("RemovedHeaders = %q, want %q", req.RemovedHeaders, tt.wantRemoved)
+			}
+			if !reflect.DeepEqual(req.UnresolvedFiles, tt.wantUnresolved) {
+				t.Errorf("UnresolvedFiles = %+v, want %+v", req.UnresolvedFiles, tt.wantUnresolved)
+			}
+			for _, w := range req.Warnings {
+				if w.Severity == SeverityWarning {
+					t.Errorf("unexpected warning: %v", w)
+				}
+			}
+		})
+	}
+}
+
+func TestCurlParser_Stdin(t *testing.T) {
+	tests := []struct {
+		name     string
+		cmd      string
+		stdin    string
+		wantBody string
+		wantList HeaderList
+	}{
+		{
+			name:     "Data strips CR and LF from stdin",
+			cmd:      `curl -d @- https://a.com`,
+			stdin:    "a=1\r\nb=2\n",
+			wantBody: "a=1b=2",
+		},
+		{
+			name:     "Data binary keeps stdin as is",
+			cmd:      `curl --data-binary @- https://a.com`,
+			stdin:    "a=1\r\nb=2\n",
+			wantBody: "a=1\r\nb=2\n",
+		},
+		{
+			name:     "Urlencode stdin",
+			cmd:      `curl --data-urlencode q@- https://a.com`,
+			stdin:    "a b",
+			wantBody: "q=a%20b",
+		},
+		{
+			name:     "Upload stdin",
+			cmd:      `curl -T - https://a.com/`,
+			stdin:    "content",
+			wantBody: "content",
+		},
+		{
+			name:     "Headers from stdin",
+			cmd:      `curl -H @- https://a.com`,
+			stdin:    "X-A: 1\nX-B: 2\n",
+			wantList: HeaderList{{Name: "X-A", Value: "1"}, {Name: "X-B", Value: "2"}},
+		},
+		{
+			name:     "Stdin is read once",
+			cmd:      `curl -d @- --data-binary @- https://a.com`,
+			stdin:    "x",
+			wantBody: "x&x",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := NewCurlParser(tt.cmd, WithStdin(strings.NewReader(tt.stdin))).Parse()
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if req.Body != tt.wantBody {
+				t.Errorf("Body = %q, want %q", req.Body, tt.wantBody)
+			}
+			if !reflect.DeepEqual(req.HeaderList, tt.wantList) {
+				t.Errorf("HeaderList = %v, want %v", req.HeaderList, tt.wantList)
+			}
+			if len(req.UnresolvedFiles) != 0 {
+				t.Errorf("UnresolvedFiles = %+v, want none", req.UnresolvedFiles)
+			}
+		})
+	}
+}
+
+func TestCurlParser_UnresolvedBodyFiles(t *testing.T) {
+	req, err := NewCurlParser(`curl -d @a.txt --data-binary @- --data-urlencode q@b.txt --data-raw @c.txt https://a.com`).Parse()
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if want := "@a.txt&@-&q@b.txt&@c.txt"; req.Body != want {
+		t.Errorf("Body = %q, want %q", req.Body, want)
+	}
+	want := []FileReference{
+		{Option: "-d", Path: "a.txt", Index: 2},
+		{Option: "--data-binary", Path: "-", Index: 4},
+		{Option: "--data-urlencode", Path: "b.txt", Index: 6},
+	}
+	if !reflect.DeepEqual(req.UnresolvedFiles, want) {
+		t.Errorf("UnresolvedFiles = %+v, want %+v", req.UnresolvedFiles, want)
+	}
+}
@@ -0,0 +1,213 @@
+package curl_parser
+
+import (
+	"strings"
+)
+
+// FormPart multipart/form-data 表单中的一个部分，对应一个 -F 或 --form-string
+type FormPart struct {
+	// 字段名
+	Name string
+	// 文本字段的值
+	Value string
+	// name=@file 上传的文件路径，"-" 表示标准输入
+	File string
+	// name=<file 从文件读取文本字段的值，"-" 表示标准输入
+	ValueFile string
+	// ;type= 指定的Content-Type
+	ContentType string
+	// ;filename= 指定的文件名，为空时上传文件使用 File 的文件名
+	Filename string
+	// ;headers= 添加的请求头
+	Headers HeaderList
+	// ;encoder= 指定的传输编码，例如 binary、8bit、base64、quoted-printable
+	Encoder string
+	// 是否来自 --form-string，此时 Value 按原文发送，不处理 @、< 和属性
+	Literal bool
+}
+
+// extractMultipart 提取 -F 和 --form-string 构成的multipart表单
+func (cp *CurlParser) extractMultipart(args []curlArg, req *HTTPRequest) error {
+	for _, arg := range args {
+		if !arg.is("form", "form-string") {
+			continue
+		}
+		req.IsMultipart = true
+
+		part, ok, err := cp.parseFormPart(req, arg)
+		if err != nil {
+			return err
+		}
+		if ok {
+			req.Multipart = append(req.Multipart, part)
+		}
+	}
+	return nil
+}
+
+// parseFormPart 按curl的规则解析 -F 和 --form-string 的参数
+// 格式为 name=content;type=...;filename=...;headers=...;encoder=...，
+// content 以 @ 开头表示上传文件，以 < 开头表示从文件读取文本内容；
+// 含有 ; 的值需要用双引号括起来，双引号中可以使用 \" 和 \\。
+func (cp *CurlParser) parseFormPart(req *HTTPRequest, arg curlArg) (FormPart, bool, error) {
+	name, content, ok := strings.Cut(arg.value, "=")
+	if !ok {
+		return FormPart{}, false, cp.report(req, arg.errorf(CodeInvalidForm, "表单字段 %q 缺少 =", arg.value))
+	}
+
+	part := FormPart{Name: name}
+	if arg.name == "form-string" {
+		part.Value, part.Literal = content, true
+		return part, true, nil
+	}
+
+	f := &formScanner{s: content}
+	switch {
+	case strings.HasPrefix(content, "@"):
+		f.pos++
+		part.File = f.value()
+	case strings.HasPrefix(content, "<"):
+		f.pos++
+		part.ValueFile = f.value()
+	default:
+		part.Value = f.value()
+	}
+
+	typeStart, typeEnd := -1, -1
+	for f.pos < len(f.s) && f.s[f.pos] == ';' {
+		f.pos++
+		f.skipSpaces()
+		rest := f.s[f.pos:]
+
+		switch {
+		case typeStart < 0 && hasPrefixFold(rest, "type="):
+			f.pos += len("type=")
+			f.skipSpaces()
+			typeStart = f.pos
+			typeEnd = f.skipSegment()
+		case hasPrefixFold(rest, "filename="):
+			f.pos += len("filename=")
+			part.Filename = f.value()
+		case hasPrefixFold(rest, "headers="):
+			f.pos += len("headers=")
+			if err := cp.parseFormHeaders(req, arg, f, &part); err != nil {
+				return FormPart{}, false, err
+			}
+		case hasPrefixFold(rest, "encoder="):
+			f.pos += len("encoder=")
+			part.Encoder = f.value()
+		case typeStart >= 0:
+			// type之后无法识别的内容属于Content-Type，例如 ;type=text/plain;charset=utf-8
+			typeEnd = f.skipSegment()
+		default:
+			if unknown, _ := f.word(); unknown != "" {
+				req.Warnings = append(req.Warnings, arg.diagnostic(SeverityWarning, CodeSuspiciousInput, "忽略未知的表单属性 %q，值中的 ; 需要用双引号括起来", unknown))
+			}
+		}
+	}
+	if typeStart >= 0 {
+		part.ContentType = f.s[typeStart:typeEnd]
+	}
+	return part, true, nil
+}
+
+// parseFormHeaders 解析 ;headers= 属性，headers=@file 或 headers=<file 从文件中逐行读取
+// 文件中的空行和以 # 开头的行会被跳过。
+func (cp *CurlParser) parseFormHeaders(req *HTTPRequest, arg curlArg, f *formScanner, part *FormPart) error {
+	if f.pos >= len(f.s) || f.s[f.pos] != '@' && f.s[f.pos] != '<' {
+		if header, _, ok := parseHeaderLine(f.value()); ok {
+			part.Headers = append(part.Headers, header)
+		}
+		return nil
+	}
+
+	f.pos++
+	data, ok, err := cp.loadFile(req, arg, f.value())
+	if err != nil || !ok {
+		return err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if header, _, ok := parseHeaderLine(line); ok {
+			part.Headers = append(part.Headers, header)
+		}
+	}
+	return nil
+}
+
+// formScanner 逐个读取 -F 参数中以 ; 分隔的内容
+type formScanner struct {
+	s   string
+	pos int
+}
+
+// skipSpaces 跳过空白字符
+func (f *formScanner) skipSpaces() {
+	for f.pos < len(f.s) && isFormSpace(f.s[f.pos]) {
+		f.pos++
+	}
+}
+
+// skipSegment 跳到下一个 ; 之前，返回去掉末尾空白后的结束位置
+func (f *formScanner) skipSegment() int {
+	end := f.pos
+	for ; f.pos < len(f.s) && f.s[f.pos] != ';'; f.pos++ {
+		if !isFormSpace(f.s[f.pos]) {
+			end = f.pos + 1
+		}
+	}
+	return end
+}
+
+// word 读取到下一个 ; 为止的内容，quoted表示是否使用了双引号
+// 双引号中的 ; 不是分隔符，闭合引号之后到下一个 ; 之间的内容会被忽略；缺少闭合引号时按没有引号处理。
+func (f *formScanner) word() (value string, quoted bool) {
+	start := f.pos
+	if f.pos < len(f.s) && f.s[f.pos] == '"' {
+		var buf strings.Builder
+		for i := f.pos + 1; i < len(f.s); i++ {
+			c := f.s[i]
+			if c == '\\' && i+1 < len(f.s) && (f.s[i+1] == '\\' || f.s[i+1] == '"') {
+				buf.WriteByte(f.s[i+1])
+				i++
+				continue
+			}
+			if c == '"' {
+				f.pos = i + 1
+				f.skipSegment()
+				return buf.String(), true
+			}
+			buf.WriteByte(c)
+		}
+	}
+	for f.pos < len(f.s) && f.s[f.pos] != ';' {
+		f.pos++
+	}
+	return f.s[start:f.pos], false
+}
+
+// value 读取一个值，去掉开头的空白，没有引号时也去掉末尾的空白
+func (f *formScanner) value() string {
+	f.skipSpaces()
+	value, quoted := f.word()
+	if !quoted {
+		value = strings.TrimRight(value, formSpaces)
+	}
+	return value
+}
+
+// formSpaces 与C的isspace相同的空白字符
+const formSpaces = " \t\n\v\f\r"
+
+// isFormSpace 判断是否为空白字符
+func isFormSpace(c byte) bool {
+	return strings.IndexByte(formSpaces, c) >= 0
+}
+
+// hasPrefixFold 不区分大小写判断前缀
+func hasPrefixFold(s, prefix string) bool {
+	return len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix)
+}
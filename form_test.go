@@ -0,0 +1,114 @@
+package curl_parser
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+func TestCurlParser_Multipart(t *testing.T) {
+	fsys := fstest.MapFS{
+		"part-headers.txt": {Data: []byte("# comment\nX-A: 1\r\n\nX-B: 2\n")},
+	}
+
+	tests := []struct {
+		name         string
+		cmd          string
+		want         []FormPart
+		wantWarnings int
+	}{
+		{
+			name: "Text fields",
+			cmd:  `curl -F a=1 -F ' b = 2 ' https://a.com`,
+			want: []FormPart{{Name: "a", Value: "1"}, {Name: " b ", Value: "2"}},
+		},
+		{
+			name: "File upload with attributes",
+			cmd:  `curl -F 'file=@dir/a.png;type=image/png;filename=b.png' https://a.com`,
+			want: []FormPart{{Name: "file", File: "dir/a.png", ContentType: "image/png", Filename: "b.png"}},
+		},
+		{
+			name: "Value from file",
+			cmd:  `curl -F 'text=<notes.txt' -F 'in=@-' https://a.com`,
+			want: []FormPart{{Name: "text", ValueFile: "notes.txt"}, {Name: "in", File: "-"}},
+		},
+		{
+			name: "Quoted values",
+			cmd:  `curl -F 'a="x;y \"z\" \\";type=text/plain' -F 'f=@"a;b.txt";filename="c \"d\".txt"' https://a.com`,
+			want: []FormPart{
+				{Name: "a", Value: `x;y "z" \`, ContentType: "text/plain"},
+				{Name: "f", File: "a;b.txt", Filename: `c "d".txt`},
+			},
+		},
+		{
+			name: "Content type parameters",
+			cmd:  `curl -F 'a=1;TYPE=text/plain; charset=utf-8 ;encoder=base64' https://a.com`,
+			want: []FormPart{{Name: "a", Value: "1", ContentType: "text/plain; charset=utf-8", Encoder: "base64"}},
+		},
+		{
+			name: "Part headers",
+			cmd:  `curl -F 'a=1;headers="X-A: a;b";headers=X-B: b' -F 'f=@x;headers=@part-headers.txt' https://a.com`,
+			want: []FormPart{
+				{Name: "a", Value: "1", Headers: HeaderList{{Name: "X-A", Value: "a;b"}, {Name: "X-B", Value: "b"}}},
+				{Name: "f", File: "x", Headers: HeaderList{{Name: "X-A", Value: "1"}, {Name: "X-B", Value: "2"}}},
+			},
+		},
+		{
+			name: "Form string is literal",
+			cmd:  `curl --form-string 'a=@x;type=text/plain' https://a.com`,
+			want: []FormPart{{Name: "a", Value: "@x;type=text/plain", Literal: true}},
+		},
+		{
+			name:         "Unknown attribute",
+			cmd:          `curl -F 'a=x;y' https://a.com`,
+			want:         []FormPart{{Name: "a", Value: "x"}},
+			wantWarnings: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := NewCurlParser(tt.cmd, WithFS(fsys)).Parse()
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if !req.IsMultipart {
+				t.Errorf("IsMultipart = false, want true")
+			}
+			if req.Method != "POST" {
+				t.Errorf("Method = %s, want POST", req.Method)
+			}
+			if !reflect.DeepEqual(req.Multipart, tt.want) {
+				t.Errorf("Multipart = %+v, want %+v", req.Multipart, tt.want)
+			}
+			var warnings int
+			for _, w := range req.Warnings {
+				if w.Severity == SeverityWarning {
+					warnings++
+				}
+			}
+			if warnings != tt.wantWarnings {
+				t.Errorf("got %d warnings, want %d: %v", warnings, tt.wantWarnings, req.Warnings)
+			}
+		})
+	}
+}
+
+func TestCurlParser_InvalidFormField(t *testing.T) {
+	cmd := `curl -F novalue -F a=1 https://a.com`
+
+	req, err := NewCurlParser(cmd).Parse()
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if want := []FormPart{{Name: "a", Value: "1"}}; !reflect.DeepEqual(req.Multipart, want) {
+		t.Errorf("Multipart = %+v, want %+v", req.Multipart, want)
+	}
+
+	_, err = NewCurlParser(cmd, WithStrict(true)).Parse()
+	var perr *ParseError
+	if !errors.As(err, &perr) || perr.Code != CodeInvalidForm {
+		t.Errorf("Parse() error = %v, want code %s", err, CodeInvalidForm)
+	}
+}
@@ -0,0 +1,79 @@
+package curl_parser
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Header 表示一个请求头，名称保留命令中的大小写
+type Header struct {
+	Name  string
+	Value string
+}
+
+// HeaderList 按命令中出现顺序排列的请求头，同名请求头可以出现多次
+type HeaderList []Header
+
+// Get 返回第一个同名请求头的值，名称不区分大小写，不存在时返回空字符串
+func (h HeaderList) Get(name string) string {
+	for _, header := range h {
+		if strings.EqualFold(header.Name, name) {
+			return header.Value
+		}
+	}
+	return ""
+}
+
+// Values 按顺序返回所有同名请求头的值，名称不区分大小写
+func (h HeaderList) Values(name string) []string {
+	var values []string
+	for _, header := range h {
+		if strings.EqualFold(header.Name, name) {
+			values = append(values, header.Value)
+		}
+	}
+	return values
+}
+
+// Has 判断是否存在同名请求头，名称不区分大小写
+func (h HeaderList) Has(name string) bool {
+	for _, header := range h {
+		if strings.EqualFold(header.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// HTTPHeader 转换为 http.Header，名称会被规范化，同名请求头的值保持原有顺序
+func (h HeaderList) HTTPHeader() http.Header {
+	result := make(http.Header, len(h))
+	for _, header := range h {
+		result.Add(header.Name, header.Value)
+	}
+	return result
+}
+
+// parseHeaderLine 按照curl的规则解析 -H 的参数
+// "Name: value" 添加请求头；"Name:" 冒号后为空表示不发送curl默认添加的同名请求头，remove返回true；
+// "Name;" 表示发送值为空的请求头。其余格式会被curl忽略，ok返回false。
+func parseHeaderLine(line string) (header Header, remove bool, ok bool) {
+	if name, value, found := strings.Cut(line, ":"); found {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return Header{}, false, false
+		}
+		value = strings.TrimSpace(value)
+		return Header{Name: name, Value: value}, value == "", true
+	}
+
+	if name, rest, found := strings.Cut(line, ";"); found && strings.TrimSpace(rest) == "" {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return Header{}, false, false
+		}
+		return Header{Name: name}, false, true
+	}
+
+	return Header{}, false, false
+}
@@ -0,0 +1,125 @@
+package curl_parser
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCurlParser_HeaderList(t *testing.T) {
+	cmd := `curl -H 'X-Forwarded-For: 10.0.0.1' -H 'accept: a' -H 'Accept: b' -H 'X-Forwarded-For: 10.0.0.2' -H 'x-sig: s' https://a.com`
+	req, err := NewCurlParser(cmd).Parse()
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	want := HeaderList{
+		{Name: "X-Forwarded-For", Value: "10.0.0.1"},
+		{Name: "accept", Value: "a"},
+		{Name: "Accept", Value: "b"},
+		{Name: "X-Forwarded-For", Value: "10.0.0.2"},
+		{Name: "x-sig", Value: "s"},
+	}
+	if !reflect.DeepEqual(req.HeaderList, want) {
+		t.Errorf("HeaderList = %v, want %v", req.HeaderList, want)
+	}
+	if req.Headers["X-Forwarded-For"] != "10.0.0.2" {
+		t.Errorf("Headers[X-Forwarded-For] = %v, want last value", req.Headers["X-Forwarded-For"])
+	}
+}
+
+func TestHeaderList(t *testing.T) {
+	h := HeaderList{
+		{Name: "accept", Value: "a"},
+		{Name: "X-Empty", Value: ""},
+		{Name: "ACCEPT", Value: "b"},
+	}
+
+	if got := h.Get("Accept"); got != "a" {
+		t.Errorf("Get(Accept) = %q, want a", got)
+	}
+	if got := h.Get("Missing"); got != "" {
+		t.Errorf("Get(Missing) = %q, want empty", got)
+	}
+	if got := h.Values("accept"); !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("Values(accept) = %q, want [a b]", got)
+	}
+	if !h.Has("x-empty") || h.Has("Missing") {
+		t.Errorf("Has() returned wrong result")
+	}
+
+	want := http.Header{
+		"Accept":  {"a", "b"},
+		"X-Empty": {""},
+	}
+	if got := h.HTTPHeader(); !reflect.DeepEqual(got, want) {
+		t.Errorf("HTTPHeader() = %v, want %v", got, want)
+	}
+}
+
+func TestCurlParser_MultipleCookieHeaders(t *testing.T) {
+	req, err := NewCurlParser(`curl -H 'cookie: a=1' -H 'Cookie: b=2' https://a.com`).Parse()
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if req.RawCookie != "a=1; b=2" {
+		t.Errorf("RawCookie = %q, want %q", req.RawCookie, "a=1; b=2")
+	}
+	if !reflect.DeepEqual(req.ParsedCookies, map[string]string{"a": "1", "b": "2"}) {
+		t.Errorf("ParsedCookies = %v", req.ParsedCookies)
+	}
+}
+
+func TestCurlParser_HeaderRemovalAndEmptyValue(t *testing.T) {
+	tests := []struct {
+		name        string
+		cmd         string
+		wantList    HeaderList
+		wantRemoved []string
+	}{
+		{
+			name:        "Colon without value removes header",
+			cmd:         `curl -H 'Accept:' -H 'User-Agent:  ' https://a.com`,
+			wantRemoved: []string{"Accept", "User-Agent"},
+		},
+		{
+			name:     "Semicolon sends empty header",
+			cmd:      `curl -H 'X-Empty;' -H 'X-Other; ' https://a.com`,
+			wantList: HeaderList{{Name: "X-Empty"}, {Name: "X-Other"}},
+		},
+		{
+			name:        "Removal keeps custom values",
+			cmd:         `curl -H 'Accept: a' -H 'Accept:' -H 'X-A: b' https://a.com`,
+			wantList:    HeaderList{{Name: "Accept", Value: "a"}, {Name: "X-A", Value: "b"}},
+			wantRemoved: []string{"Accept"},
+		},
+		{
+			name: "Invalid lines are ignored",
+			cmd:  `curl -H 'X-A;b' -H ': v' -H 'NoColon' https://a.com`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := NewCurlParser(tt.cmd).Parse()
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if !reflect.DeepEqual(req.HeaderList, tt.wantList) {
+				t.Errorf("HeaderList = %v, want %v", req.HeaderList, tt.wantList)
+			}
+			if !reflect.DeepEqual(req.RemovedHeaders, tt.wantRemoved) {
+				t.Errorf("RemovedHeaders = %q, want %q", req.RemovedHeaders, tt.wantRemoved)
+			}
+			for _, name := range tt.wantRemoved {
+				if _, ok := req.Headers[name]; ok && !tt.wantList.Has(name) {
+					t.Errorf("Headers contains removed header %s", name)
+				}
+				if !req.HeaderRemoved(strings.ToLower(name)) {
+					t.Errorf("HeaderRemoved(%s) = false, want true", name)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package curl_parser
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/textproto"
+	"path"
+	"strings"
+)
+
+// boundaryPrefix 与curl生成的boundary使用相同的前缀
+const boundaryPrefix = "------------------------"
+
+// fileContentTypes curl根据文件扩展名推断的Content-Type
+var fileContentTypes = map[string]string{
+	".gif":  "image/gif",
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".png":  "image/png",
+	".svg":  "image/svg+xml",
+	".txt":  "text/plain",
+	".htm":  "text/html",
+	".html": "text/html",
+	".pdf":  "application/pdf",
+	".xml":  "application/xml",
+}
+
+// formNameEscaper 与curl相同，按HTML5的规则转义字段名和文件名中的双引号和换行
+var formNameEscaper = strings.NewReplacer(`"`, "%22", "\r", "%0D", "\n", "%0A")
+
+// MultipartBody 生成 -F 表单对应的multipart/form-data请求体，返回请求体和带有boundary的Content-Type
+// @file 和 <file 引用的文件通过 fsys 读取。boundary为空时根据表单内容生成，
+// 相同的表单总是得到相同的请求体，便于计算长度和签名。
+func (r *HTTPRequest) MultipartBody(fsys fs.FS, boundary string) ([]byte, string, error) {
+	if !r.IsMultipart {
+		return nil, "", errors.New("请求中没有 -F 表单")
+	}
+
+	contents := make([][]byte, len(r.Multipart))
+	for i, part := range r.Multipart {
+		data, err := part.content(fsys)
+		if err != nil {
+			return nil, "", err
+		}
+		contents[i] = data
+	}
+	if boundary == "" {
+		boundary = multipartBoundary(r.Multipart, contents)
+	}
+
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	if err := w.SetBoundary(boundary); err != nil {
+		return nil, "", fmt.Errorf("无效的boundary %q: %w", boundary, err)
+	}
+	for i, part := range r.Multipart {
+		pw, err := w.CreatePart(part.mimeHeader())
+		if err != nil {
+			return nil, "", err
+		}
+		if err := writeEncoded(pw, part.Encoder, contents[i]); err != nil {
+			return nil, "", fmt.Errorf("表单字段 %s: %w", part.Name, err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), w.FormDataContentType(), nil
+}
+
+// content 返回表单字段发送的内容
+func (p FormPart) content(fsys fs.FS) ([]byte, error) {
+	name := p.File
+	if name == "" {
+		name = p.ValueFile
+	}
+	switch {
+	case name == "":
+		return []byte(p.Value), nil
+	case name == "-":
+		return nil, fmt.Errorf("表单字段 %s 引用了标准输入，无法读取", p.Name)
+	case fsys == nil:
+		return nil, fmt.Errorf("表单字段 %s 引用了文件 %s，需要提供文件系统", p.Name, name)
+	}
+
+	data, err := fs.ReadFile(fsys, fsPath(name))
+	if err != nil {
+		return nil, fmt.Errorf("读取文件 %s 失败: %w", name, err)
+	}
+	return data, nil
+}
+
+// filename 返回Content-Disposition中的文件名，上传文件时默认使用文件路径的最后一部分
+func (p FormPart) filename() string {
+	if p.Filename != "" || p.File == "" {
+		return p.Filename
+	}
+	return path.Base(strings.ReplaceAll(p.File, `\`, "/"))
+}
+
+// mimeHeader 生成表单字段的请求头，;headers= 中指定的同名请求头优先
+func (p FormPart) mimeHeader() textproto.MIMEHeader {
+	header := make(textproto.MIMEHeader)
+
+	disposition := `form-data; name="` + formNameEscaper.Replace(p.Name) + `"`
+	filename := p.filename()
+	if filename != "" {
+		disposition += `; filename="` + formNameEscaper.Replace(filename) + `"`
+	}
+	header.Set("Content-Disposition", disposition)
+
+	contentType := p.ContentType
+	if contentType == "" && filename != "" {
+		contentType = fileContentTypes[strings.ToLower(path.Ext(filename))]
+		if contentType == "" && p.File != "" {
+			contentType = "application/octet-stream"
+		}
+	}
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+	if p.Encoder != "" {
+		header.Set("Content-Transfer-Encoding", p.Encoder)
+	}
+
+	for _, h := range p.Headers {
+		header.Del(h.Name)
+	}
+	for _, h := range p.Headers {
+		header.Add(h.Name, h.Value)
+	}
+	return header
+}
+
+// multipartBoundary 根据表单内容生成固定的boundary
+func multipartBoundary(parts []FormPart, contents [][]byte) string {
+	h := sha256.New()
+	for i, part := range parts {
+		fmt.Fprintf(h, "%q %q %q %q %v\n", part.Name, part.filename(), part.ContentType, part.Encoder, part.Headers)
+		h.Write(contents[i])
+	}
+	return boundaryPrefix + hex.EncodeToString(h.Sum(nil))[:24]
+}
+
+// writeEncoded 按 ;encoder= 指定的传输编码写入内容
+func writeEncoded(w io.Writer, encoder string, data []byte) error {
+	switch strings.ToLower(encoder) {
+	case "", "binary", "8bit", "7bit":
+		_, err := w.Write(data)
+		return err
+	case "base64":
+		// 与curl一致，每行76个字符
+		encoded := base64.StdEncoding.EncodeToString(data)
+		for len(encoded) > 76 {
+			if _, err := io.WriteString(w, encoded[:76]+"\r\n"); err != nil {
+				return err
+			}
+			encoded = encoded[76:]
+		}
+		_, err := io.WriteString(w, encoded)
+		return err
+	case "quoted-printable":
+		qw := quotedprintable.NewWriter(w)
+		if _, err := qw.Write(data); err != nil {
+			return err
+		}
+		return qw.Close()
+	default:
+		return fmt.Errorf("不支持的编码 %s", encoder)
+	}
+}
@@ -0,0 +1,129 @@
+package curl_parser
+
+import (
+	"io"
+	"mime"
+	"mime/multipart"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestHTTPRequest_MultipartBody(t *testing.T) {
+	fsys := fstest.MapFS{
+		"dir/a.png": {Data: []byte("\x89PNG")},
+		"notes.txt": {Data: []byte("hello\n")},
+		"data.bin":  {Data: []byte("raw")},
+	}
+	cmd := `curl -F 'file=@dir/a.png' -F 'text=<notes.txt' -F 'q="a\"b";filename=x.txt' -F 'bin=@data.bin;headers="X-A: 1"' --form-string 'raw=@literal' -F 'enc=hi;encoder=base64;type=text/plain' https://a.com`
+	req, err := NewCurlParser(cmd).Parse()
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	body, contentType, err := req.MultipartBody(fsys, "b")
+	if err != nil {
+		t.Fatalf("MultipartBody() error = %v", err)
+	}
+	if contentType != "multipart/form-data; boundary=b" {
+		t.Errorf("contentType = %s", contentType)
+	}
+	want := "--b\r\n" +
+		"Content-Disposition: form-data; name=\"file\"; filename=\"a.png\"\r\n" +
+		"Content-Type: image/png\r\n" +
+		"\r\n" +
+		"\x89PNG\r\n" +
+		"--b\r\n" +
+		"Content-Disposition: form-data; name=\"text\"\r\n" +
+		"\r\n" +
+		"hello\n\r\n" +
+		"--b\r\n" +
+		"Content-Disposition: form-data; name=\"q\"; filename=\"x.txt\"\r\n" +
+		"Content-Type: text/plain\r\n" +
+		"\r\n" +
+		"a\"b\r\n" +
+		"--b\r\n" +
+		"Content-Disposition: form-data; name=\"bin\"; filename=\"data.bin\"\r\n" +
+		"Content-Type: application/octet-stream\r\n" +
+		"X-A: 1\r\n" +
+		"\r\n" +
+		"raw\r\n" +
+		"--b\r\n" +
+		"Content-Disposition: form-data; name=\"raw\"\r\n" +
+		"\r\n" +
+		"@literal\r\n" +
+		"--b\r\n" +
+		"Content-Disposition: form-data; name=\"enc\"\r\n" +
+		"Content-Transfer-Encoding: base64\r\n" +
+		"Content-Type: text/plain\r\n" +
+		"\r\n" +
+		"aGk=\r\n" +
+		"--b--\r\n"
+	if string(body) != want {
+		t.Errorf("MultipartBody() =\n%q\nwant\n%q", body, want)
+	}
+}
+
+func TestHTTPRequest_MultipartBodyDeterministicBoundary(t *testing.T) {
+	req, err := NewCurlParser(`curl -F 'a=1' -F 'b="x\"y";filename="q\"z.txt"' https://a.com`).Parse()
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	body, contentType, err := req.MultipartBody(nil, "")
+	if err != nil {
+		t.Fatalf("MultipartBody() error = %v", err)
+	}
+	again, _, err := req.MultipartBody(nil, "")
+	if err != nil || string(again) != string(body) {
+		t.Errorf("MultipartBody() is not deterministic")
+	}
+
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil || mediaType != "multipart/form-data" || !strings.HasPrefix(params["boundary"], boundaryPrefix) {
+		t.Fatalf("contentType = %s", contentType)
+	}
+	r := multipart.NewReader(strings.NewReader(string(body)), params["boundary"])
+	var got []string
+	for {
+		part, err := r.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("NextPart() error = %v", err)
+		}
+		data, _ := io.ReadAll(part)
+		got = append(got, part.FormName()+"="+string(data))
+		if part.FormName() == "b" && !strings.Contains(part.Header.Get("Content-Disposition"), `filename="q%22z.txt"`) {
+			t.Errorf("Content-Disposition = %s", part.Header.Get("Content-Disposition"))
+		}
+	}
+	if want := []string{"a=1", `b=x"y`}; strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("parts = %q, want %q", got, want)
+	}
+}
+
+func TestHTTPRequest_MultipartBodyErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		cmd  string
+	}{
+		{name: "Not multipart", cmd: `curl -d a=1 https://a.com`},
+		{name: "File without file system", cmd: `curl -F f=@a.txt https://a.com`},
+		{name: "Stdin", cmd: `curl -F f=@- https://a.com`},
+		{name: "Unknown encoder", cmd: `curl -F 'f=1;encoder=gzip' https://a.com`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := NewCurlParser(tt.cmd).Parse()
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if _, _, err := req.MultipartBody(nil, "b"); err == nil {
+				t.Errorf("MultipartBody() error = nil, want error")
+			}
+		})
+	}
+}
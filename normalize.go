@@ -0,0 +1,40 @@
+package curl_parser
+
+import (
+	"net/http"
+	"sort"
+)
+
+// Normalize 解析curl命令并按统一的格式重新生成，便于对保存的命令去重和比较
+// 生成的命令只有一行，使用长选项，请求头名称转换为规范格式并排序，
+// 多个 -d 合并为一个 --data-raw，可以由其他选项推断的 -X 会被去掉。
+// 解析结果中没有对应字段、但会改变请求的选项（例如 --oauth2-bearer、--url-query）按选项名排序后保留在最后；
+// 只影响输出的选项（例如 -o、-s）和宽松模式下跳过的未知选项会被去掉，需要拒绝未知选项时使用 WithStrict(true)。
+// opts 为解析选项，例如使用 WithDialect 规范化Windows命令；使用 WithFS 时引用的文件内容会写入命令。
+func Normalize(cmd string, opts ...Option) (string, error) {
+	req, err := NewCurlParser(cmd, opts...).Parse()
+	if err != nil {
+		return "", err
+	}
+
+	for i := range req.HeaderList {
+		req.HeaderList[i].Name = http.CanonicalHeaderKey(req.HeaderList[i].Name)
+	}
+	for i, name := range req.RemovedHeaders {
+		req.RemovedHeaders[i] = http.CanonicalHeaderKey(name)
+	}
+	normalized := Generate(req, WithSortedHeaders())
+
+	// 同名选项保持原来的顺序，例如多个 --url-query 的顺序会影响查询字符串
+	unsupported := append([]curlArg(nil), req.unsupported...)
+	sort.SliceStable(unsupported, func(i, j int) bool {
+		return unsupported[i].name < unsupported[j].name
+	})
+	for _, arg := range unsupported {
+		normalized += " --" + arg.name
+		if longOptions[arg.name].hasArg {
+			normalized += " " + shellQuote(arg.value)
+		}
+	}
+	return normalized, nil
+}
@@ -0,0 +1,110 @@
+package curl_parser
+
+import (
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name string
+		cmds []string
+		want string
+	}{
+		{
+			name: "Redundant method and option order",
+			cmds: []string{
+				`curl https://a.com/p`,
+				`curl -X GET "https://a.com/p"`,
+				`curl --request get --url https://a.com/p`,
+			},
+			want: `curl https://a.com/p`,
+		},
+		{
+			name: "Sorted headers and merged data",
+			cmds: []string{
+				`curl -X POST https://a.com -H 'x-b: 2' -H 'Content-Type: text/plain' -H 'X-A: 1' -d a=1 -d b=2`,
+				`curl --data 'a=1&b=2' --header "content-type: text/plain" --header "X-A: 1" --header "X-B: 2" https://a.com`,
+				"curl https://a.com \\\n  -H 'X-A: 1' \\\n  -H 'X-B: 2' \\\n  -H 'Content-Type: text/plain' \\\n  --data-raw a=1 \\\n  --data-ascii b=2",
+			},
+			want: `curl https://a.com --header 'Content-Type: text/plain' --header 'X-A: 1' --header 'X-B: 2' --data-raw 'a=1&b=2'`,
+		},
+		{
+			name: "Duplicate headers keep their order",
+			cmds: []string{
+				`curl -H 'X-Multi: 2' -H 'Accept:' -H 'A: 0' -H 'x-multi: 1' https://a.com`,
+			},
+			want: `curl https://a.com --header 'A: 0' --header 'X-Multi: 2' --header 'X-Multi: 1' --header Accept:`,
+		},
+		{
+			name: "Windows cmd",
+			cmds: []string{
+				`curl ^"https://a.com/p?q=1^" -H ^"Accept: */*^" --compressed -s`,
+				`curl 'https://a.com/p?q=1' --compressed -H 'accept: */*'`,
+			},
+			want: `curl 'https://a.com/p?q=1' --header 'Accept: */*' --compressed`,
+		},
+		{
+			name: "Options without a field are kept",
+			cmds: []string{
+				`curl --url-query b=2 -U 'p:q' --oauth2-bearer tok --url-query a=1 https://x.com -o out`,
+				`curl https://x.com --oauth2-bearer tok --proxy-user p:q --url-query b=2 --url-query a=1`,
+			},
+			want: `curl https://x.com --oauth2-bearer tok --proxy-user p:q --url-query b=2 --url-query a=1`,
+		},
+		{
+			name: "Get with data",
+			cmds: []string{
+				`curl -G -d q=1 https://a.com/search`,
+				`curl 'https://a.com/search?q=1'`,
+			},
+			want: `curl 'https://a.com/search?q=1'`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, cmd := range tt.cmds {
+				got, err := Normalize(cmd)
+				if err != nil {
+					t.Fatalf("Normalize(%s) error = %v", cmd, err)
+				}
+				if got != tt.want {
+					t.Errorf("Normalize(%s) =\n%s\nwant\n%s", cmd, got, tt.want)
+				}
+				if again, _ := Normalize(got); again != got {
+					t.Errorf("Normalize(%s) = %s, want unchanged", got, again)
+				}
+			}
+		})
+	}
+}
+
+func TestNormalize_Distinct(t *testing.T) {
+	// 只有没有对应字段的选项不同时，规范化结果也必须不同
+	pairs := [][2]string{
+		{`curl --oauth2-bearer tok https://x.com`, `curl https://x.com`},
+		{`curl --url-query a=b https://x.com`, `curl https://x.com`},
+		{`curl --digest -u a:b https://x.com`, `curl -u a:b https://x.com`},
+		{`curl --url-query a=1 --url-query b=2 https://x.com`, `curl --url-query b=2 --url-query a=1 https://x.com`},
+	}
+
+	for _, pair := range pairs {
+		a, err := Normalize(pair[0])
+		if err != nil {
+			t.Fatalf("Normalize(%s) error = %v", pair[0], err)
+		}
+		b, err := Normalize(pair[1])
+		if err != nil {
+			t.Fatalf("Normalize(%s) error = %v", pair[1], err)
+		}
+		if a == b {
+			t.Errorf("Normalize(%s) = Normalize(%s) = %s", pair[0], pair[1], a)
+		}
+	}
+}
+
+func TestNormalize_Error(t *testing.T) {
+	if _, err := Normalize(`curl -H "X-A: 1"`); err == nil {
+		t.Error("Normalize() error = nil, want error")
+	}
+}
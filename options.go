@@ -0,0 +1,440 @@
+package curl_parser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// optionSpec 描述一个curl选项
+type optionSpec struct {
+	// 长选项名，不含前缀 "--"
+	long string
+	// 短选项字母，没有短选项时为0
+	short byte
+	// 是否需要参数
+	hasArg bool
+	// 带参数的选项能否多次出现，不能多次出现的选项以最后一次为准
+	repeatable bool
+	// HTTPRequest中是否有对应的字段，其余选项只识别参数，解析时会给出 CodeIgnoredOption 提示
+	supported bool
+	// 没有对应字段的选项是否会改变curl发送的请求，例如 --oauth2-bearer、--url-query、--compressed，
+	// 解析时给出 CodeUnsupportedOption 警告；其余选项只影响输出或连接方式，例如 -o、-s、--retry
+	lossy bool
+}
+
+// curlOptions curl支持的选项列表
+// 即使解析器不使用某个选项，也需要知道它是否带参数，否则它的参数会被误认为URL。
+var curlOptions = []optionSpec{
+	// 基础HTTP
+	{long: "request", short: 'X', hasArg: true, supported: true},
+	{long: "url", hasArg: true, repeatable: true, supported: true},
+	{long: "header", short: 'H', hasArg: true, repeatable: true, supported: true},
+	{long: "data", short: 'd', hasArg: true, repeatable: true, supported: true},
+	{long: "data-raw", hasArg: true, repeatable: true, supported: true},
+	{long: "data-binary", hasArg: true, repeatable: true, supported: true},
+	{long: "data-ascii", hasArg: true, repeatable: true, supported: true},
+	{long: "data-urlencode", hasArg: true, repeatable: true, supported: true},
+	{long: "json", hasArg: true, repeatable: true, supported: true},
+	{long: "form", short: 'F', hasArg: true, repeatable: true, supported: true},
+	{long: "form-string", hasArg: true, repeatable: true, supported: true},
+	{long: "get", short: 'G', supported: true},
+	{long: "head", short: 'I', supported: true},
+	{long: "upload-file", short: 'T', hasArg: true, repeatable: true, supported: true},
+	{long: "request-target", hasArg: true, lossy: true},
+	{long: "url-query", hasArg: true, repeatable: true, lossy: true},
+
+	// Cookie
+	{long: "cookie", short: 'b', hasArg: true, repeatable: true, supported: true},
+	{long: "cookie-jar", short: 'c', hasArg: true, supported: true},
+	{long: "junk-session-cookies", short: 'j'},
+
+	// 认证与安全
+	{long: "user", short: 'u', hasArg: true, supported: true},
+	{long: "user-agent", short: 'A', hasArg: true, supported: true},
+	{long: "referer", short: 'e', hasArg: true, supported: true},
+	{long: "oauth2-bearer", hasArg: true, lossy: true},
+	{long: "basic"},
+	{long: "digest", lossy: true},
+	{long: "ntlm", lossy: true},
+	{long: "negotiate", lossy: true},
+	{long: "anyauth", lossy: true},
+	{long: "aws-sigv4", hasArg: true, lossy: true},
+	{long: "netrc", short: 'n', lossy: true},
+	{long: "netrc-optional", lossy: true},
+	{long: "netrc-file", hasArg: true, lossy: true},
+	{long: "insecure", short: 'k', supported: true},
+	{long: "cacert", hasArg: true, supported: true},
+	{long: "capath", hasArg: true},
+	{long: "cert", short: 'E', hasArg: true, lossy: true},
+	{long: "cert-type", hasArg: true, lossy: true},
+	{long: "key", hasArg: true, lossy: true},
+	{long: "key-type", hasArg: true, lossy: true},
+	{long: "pass", hasArg: true, lossy: true},
+	{long: "pinnedpubkey", hasArg: true},
+	{long: "ssl-no-revoke"},
+	{long: "tlsv1", short: '1'},
+	{long: "tlsv1.0"},
+	{long: "tlsv1.1"},
+	{long: "tlsv1.2"},
+	{long: "tlsv1.3"},
+	{long: "tls-max", hasArg: true},
+	{long: "ciphers", hasArg: true},
+	{long: "crlfile", hasArg: true},
+
+	// 网络配置
+	{long: "proxy", short: 'x', hasArg: true, supported: true},
+	{long: "proxy-user", short: 'U', hasArg: true, lossy: true},
+	{long: "proxy-header", hasArg: true, repeatable: true, lossy: true},
+	{long: "proxy-insecure"},
+	{long: "proxy-cacert", hasArg: true},
+	{long: "proxy-capath", hasArg: true},
+	{long: "proxy-cert", hasArg: true, lossy: true},
+	{long: "proxy-cert-type", hasArg: true, lossy: true},
+	{long: "proxy-key", hasArg: true, lossy: true},
+	{long: "proxy-key-type", hasArg: true, lossy: true},
+	{long: "proxy-pass", hasArg: true, lossy: true},
+	{long: "proxy-ciphers", hasArg: true},
+	{long: "proxy-basic"},
+	{long: "proxy-digest", lossy: true},
+	{long: "proxy-ntlm", lossy: true},
+	{long: "proxy-anyauth", lossy: true},
+	{long: "proxytunnel", short: 'p'},
+	{long: "preproxy", hasArg: true, lossy: true},
+	{long: "noproxy", hasArg: true, lossy: true},
+	{long: "socks4", hasArg: true, lossy: true},
+	{long: "socks4a", hasArg: true, lossy: true},
+	{long: "socks5", hasArg: true, lossy: true},
+	{long: "socks5-hostname", hasArg: true, lossy: true},
+	{long: "doh-url", hasArg: true},
+	{long: "dns-servers", hasArg: true},
+	{long: "dns-interface", hasArg: true},
+	{long: "dns-ipv4-addr", hasArg: true},
+	{long: "dns-ipv6-addr", hasArg: true},
+	{long: "local-port", hasArg: true},
+	{long: "expect100-timeout", hasArg: true},
+	{long: "happy-eyeballs-timeout-ms", hasArg: true},
+	{long: "connect-timeout", hasArg: true, supported: true},
+	{long: "max-time", short: 'm', hasArg: true, supported: true},
+	{long: "location", short: 'L', supported: true},
+	{long: "location-trusted", supported: true},
+	{long: "max-redirs", hasArg: true, supported: true},
+	{long: "post301", lossy: true},
+	{long: "post302", lossy: true},
+	{long: "post303", lossy: true},
+	{long: "resolve", hasArg: true, repeatable: true, lossy: true},
+	{long: "connect-to", hasArg: true, repeatable: true, lossy: true},
+	{long: "interface", hasArg: true},
+	{long: "unix-socket", hasArg: true, lossy: true},
+	{long: "abstract-unix-socket", hasArg: true, lossy: true},
+	{long: "ipv4", short: '4'},
+	{long: "ipv6", short: '6'},
+	{long: "http1.0", short: '0', lossy: true},
+	{long: "http1.1"},
+	{long: "http2"},
+	{long: "http2-prior-knowledge"},
+	{long: "http3"},
+	{long: "compressed", lossy: true},
+	{long: "tr-encoding", lossy: true},
+	{long: "keepalive-time", hasArg: true},
+	{long: "no-keepalive"},
+	{long: "tcp-nodelay"},
+	{long: "limit-rate", hasArg: true},
+	{long: "speed-limit", short: 'Y', hasArg: true},
+	{long: "speed-time", short: 'y', hasArg: true},
+	{long: "retry", hasArg: true},
+	{long: "retry-delay", hasArg: true},
+	{long: "retry-max-time", hasArg: true},
+	{long: "retry-all-errors"},
+	{long: "retry-connrefused"},
+	{long: "path-as-is", lossy: true},
+	{long: "globoff", short: 'g'},
+	{long: "range", short: 'r', hasArg: true, lossy: true},
+	{long: "continue-at", short: 'C', hasArg: true, lossy: true},
+	{long: "time-cond", short: 'z', hasArg: true, lossy: true},
+	{long: "max-filesize", hasArg: true},
+	{long: "proto", hasArg: true},
+	{long: "proto-redir", hasArg: true},
+	{long: "alt-svc", hasArg: true},
+	{long: "hsts", hasArg: true},
+	{long: "etag-save", hasArg: true},
+	{long: "etag-compare", hasArg: true, lossy: true},
+
+	// 输出与调试
+	{long: "output", short: 'o', hasArg: true, repeatable: true},
+	{long: "remote-name", short: 'O'},
+	{long: "remote-header-name", short: 'J'},
+	{long: "output-dir", hasArg: true},
+	{long: "create-dirs"},
+	{long: "dump-header", short: 'D', hasArg: true},
+	{long: "include", short: 'i'},
+	{long: "silent", short: 's'},
+	{long: "show-error", short: 'S'},
+	{long: "verbose", short: 'v'},
+	{long: "trace", hasArg: true},
+	{long: "trace-ascii", hasArg: true},
+	{long: "trace-time"},
+	{long: "stderr", hasArg: true},
+	{long: "write-out", short: 'w', hasArg: true},
+	{long: "progress-bar", short: '#'},
+	{long: "no-progress-meter"},
+	{long: "no-buffer", short: 'N'},
+	{long: "fail", short: 'f', supported: true},
+	{long: "fail-with-body", supported: true},
+	{long: "fail-early"},
+	{long: "raw"},
+	{long: "config", short: 'K', hasArg: true, lossy: true},
+	{long: "parallel", short: 'Z'},
+	{long: "next", short: ':'},
+	{long: "variable", hasArg: true, repeatable: true},
+}
+
+var (
+	longOptions  = make(map[string]*optionSpec)
+	shortOptions = make(map[byte]*optionSpec)
+)
+
+func init() {
+	for i := range curlOptions {
+		spec := &curlOptions[i]
+		longOptions[spec.long] = spec
+		if spec.short != 0 {
+			shortOptions[spec.short] = spec
+		}
+	}
+}
+
+// curlArg 表示遍历argv后得到的一个参数
+type curlArg struct {
+	// 规范化后的长选项名，位置参数（URL）为空
+	name string
+	// 命令中的写法，例如 "-H" 或 "--header"
+	flag string
+	// 选项的参数或位置参数本身
+	value string
+	// 是否为 --no-xxx 形式的布尔选项
+	negated bool
+	// 选项在argv中的下标（包含开头的curl）
+	index int
+	// 选项在原始命令中的字节偏移
+	offset int
+	// 位置参数紧跟在宽松模式跳过的未知选项之后时为该选项，此时它可能是未知选项的参数
+	unknownBefore string
+	// 参数在argv中的下标和在原始命令中的字节偏移，参数与选项写在一起时（如 -XPOST）下标与选项相同，偏移指向参数本身
+	valueIndex  int
+	valueOffset int
+}
+
+// errorf 构造指向该参数的值的解析错误
+func (a curlArg) errorf(code ErrorCode, format string, v ...any) *ParseError {
+	return &ParseError{
+		Code:    code,
+		Option:  a.flag,
+		Index:   a.valueIndex,
+		Offset:  a.valueOffset,
+		Message: fmt.Sprintf(format, v...),
+	}
+}
+
+// parseArgs 将curl命令切分为argv并逐个识别选项
+// 未知选项、缺少参数和重复选项通过 cp.report 处理，宽松模式下会被跳过。
+func (cp *CurlParser) parseArgs(req *HTTPRequest) ([]curlArg, error) {
+	dialect := cp.dialect
+	if dialect == DialectAuto {
+		dialect = detectDialect(cp.curlCommand)
+	}
+
+	tokens, err := tokenize(cp.curlCommand, dialect)
+	if err != nil {
+		return nil, err
+	}
+
+	// 移除开头的curl，不同方言的程序名写法不同
+	first := skipProgramName(tokens, dialect)
+
+	var args []curlArg
+	seen := make(map[string]bool)
+	// add 记录一个参数，并检查单值选项是否重复
+	add := func(arg curlArg, spec *optionSpec) error {
+		if spec.hasArg && !spec.repeatable {
+			if seen[spec.long] {
+				perr := &ParseError{
+					Code:    CodeDuplicateOption,
+					Option:  arg.flag,
+					Index:   arg.index,
+					Offset:  arg.offset,
+					Message: "选项重复，以最后一次为准",
+				}
+				if err := cp.report(req, perr); err != nil {
+					return err
+				}
+			}
+			seen[spec.long] = true
+		}
+		args = append(args, arg)
+		return nil
+	}
+
+	var (
+		// 上一个参数是被跳过的未知选项
+		unknown string
+		// 是否已经遇到了表示选项结束的 --
+		endOfOptions bool
+	)
+	for i := first; i < len(tokens); i++ {
+		tok := tokens[i]
+		v := tok.value
+		unknownBefore := unknown
+		unknown = ""
+
+		switch {
+		case endOfOptions || v == "-" || !strings.HasPrefix(v, "-"):
+			args = append(args, curlArg{value: v, index: i, offset: tok.offset, valueIndex: i, valueOffset: tok.offset, unknownBefore: unknownBefore})
+
+		case v == "--":
+			// -- 之后的参数都是位置参数，例如以 - 开头的URL
+			endOfOptions = true
+
+		case strings.HasPrefix(v, "--"):
+			spec, negated := lookupLongOption(v[2:])
+			if spec == nil {
+				// 未知选项无法得知是否带参数，宽松模式下按不带参数处理
+				if err := cp.report(req, unknownOption(v, i, tok.offset)); err != nil {
+					return nil, err
+				}
+				unknown = v
+				continue
+			}
+			arg := curlArg{name: spec.long, flag: v, negated: negated, index: i, offset: tok.offset}
+			if spec.hasArg {
+				if i+1 >= len(tokens) {
+					if err := cp.report(req, missingArgument(v, i, tok.offset)); err != nil {
+						return nil, err
+					}
+					continue
+				}
+				i++
+				arg.value = tokens[i].value
+				arg.valueIndex, arg.valueOffset = i, tokens[i].offset
+			}
+			if err := add(arg, spec); err != nil {
+				return nil, err
+			}
+
+		default:
+			// 短选项可以合并，例如 -sSL；带参数的短选项可以直接拼接参数，例如 -XPOST
+			for j := 1; j < len(v); j++ {
+				flag := "-" + string(v[j])
+				spec := shortOptions[v[j]]
+				if spec == nil {
+					if err := cp.report(req, unknownOption(flag, i, tok.offset)); err != nil {
+						return nil, err
+					}
+					if j == len(v)-1 {
+						unknown = flag
+					}
+					continue
+				}
+				arg := curlArg{name: spec.long, flag: flag, index: i, offset: tok.offset}
+				if spec.hasArg {
+					if j+1 < len(v) {
+						arg.value = v[j+1:]
+						arg.valueIndex, arg.valueOffset = i, tok.offsetOf(j+1)
+					} else if i+1 < len(tokens) {
+						i++
+						arg.value = tokens[i].value
+						arg.valueIndex, arg.valueOffset = i, tokens[i].offset
+					} else {
+						if err := cp.report(req, missingArgument(flag, i, tok.offset)); err != nil {
+							return nil, err
+						}
+						break
+					}
+					if err := add(arg, spec); err != nil {
+						return nil, err
+					}
+					break
+				}
+				if err := add(arg, spec); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	return args, nil
+}
+
+// missingArgument 构造选项缺少参数的错误
+func missingArgument(flag string, index, offset int) *ParseError {
+	return &ParseError{
+		Code:    CodeMissingArgument,
+		Option:  flag,
+		Index:   index,
+		Offset:  offset,
+		Message: "缺少选项参数",
+	}
+}
+
+// unknownOption 构造未知选项的错误
+func unknownOption(flag string, index, offset int) *ParseError {
+	return &ParseError{
+		Code:    CodeUnknownOption,
+		Option:  flag,
+		Index:   index,
+		Offset:  offset,
+		Message: "未知选项",
+	}
+}
+
+// lookupLongOption 查找长选项，支持布尔选项的 --no-xxx 形式
+func lookupLongOption(name string) (*optionSpec, bool) {
+	if spec, ok := longOptions[name]; ok {
+		return spec, false
+	}
+	if rest, ok := strings.CutPrefix(name, "no-"); ok {
+		if spec, found := longOptions[rest]; found && !spec.hasArg {
+			return spec, true
+		}
+	}
+	return nil, false
+}
+
+// is 判断参数是否为指定选项之一
+func (a curlArg) is(names ...string) bool {
+	for _, name := range names {
+		if a.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// hasOption 判断是否出现过任意一个指定选项
+func hasOption(args []curlArg, names ...string) bool {
+	for _, arg := range args {
+		if arg.is(names...) {
+			return true
+		}
+	}
+	return false
+}
+
+// lastValue 返回指定选项最后一次出现时的参数，curl中单值选项以最后一次为准
+func lastValue(args []curlArg, names ...string) (string, bool) {
+	for i := len(args) - 1; i >= 0; i-- {
+		if args[i].is(names...) {
+			return args[i].value, true
+		}
+	}
+	return "", false
+}
+
+// flagEnabled 判断布尔选项最终是否开启，--no-xxx 会关闭之前的设置
+func flagEnabled(args []curlArg, names ...string) bool {
+	for i := len(args) - 1; i >= 0; i-- {
+		if args[i].is(names...) {
+			return !args[i].negated
+		}
+	}
+	return false
+}
@@ -2,8 +2,11 @@ package curl_parser
 
 import (
 	"fmt"
+	"io"
+	"io/fs"
+	"math"
 	"net/url"
-	"regexp"
+	"strconv"
 	"strings"
 )
 
@@ -15,10 +18,24 @@ type HTTPRequest struct {
 	// example: https://www.example.foo/
 	BaseURL string
 	// example: /bar
-	Path    string
+	Path string
+	// 请求头，同名请求头只保留最后一个
 	Headers map[string]string
-	Body    string
-	Query   map[string]string
+	// 按出现顺序排列的请求头，同名请求头会全部保留，名称保持命令中的大小写
+	// -H "Name;" 表示发送值为空的请求头，会以空值出现在这里
+	HeaderList HeaderList
+	// 通过 -H "Name:" 移除的curl默认请求头，例如 Accept、User-Agent
+	RemovedHeaders []string
+	Body           string
+	// -T 上传的文件路径，"-" 表示标准输入
+	UploadFile string
+	// -F、--form-string 构成的multipart表单，按出现顺序排列
+	Multipart []FormPart
+	// 为true时请求体是multipart/form-data，Content-Type需要带上生成的boundary
+	IsMultipart bool
+	// 分类后的请求体，没有请求体时为nil
+	TypedBody *Body
+	Query     map[string]string
 	// 原始Cookie字符串，例如: "name1=value1; name2=value2"
 	RawCookie string
 	// 解析后的Cookie键值对
@@ -31,9 +48,9 @@ type HTTPRequest struct {
 	Referer string
 	// 代理服务器
 	Proxy string
-	// 连接超时时间（秒）
+	// 连接超时时间（秒），小数部分向上取整，例如 0.5 为 1
 	ConnectTimeout int
-	// 最大请求时间（秒）
+	// 最大请求时间（秒），小数部分向上取整
 	MaxTime int
 	// SSL选项
 	Insecure bool
@@ -43,18 +60,64 @@ type HTTPRequest struct {
 	CookieJar string
 	// 是否跟随重定向
 	FollowRedirects bool
+	// 跟随重定向时的最大次数，默认与curl相同为50，-1表示不限制
+	MaxRedirects int
+	// 是否使用了 -f/--fail 或 --fail-with-body，状态码大于等于400时curl会失败
+	FailOnError bool
+	// 没有配置文件系统时无法读取的 @file 引用
+	UnresolvedFiles []FileReference
+	// 解析时 WithFS 指定的文件系统，生成multipart请求体时使用
+	fsys fs.FS
+	// 会改变请求但没有对应字段的选项，例如 --oauth2-bearer，Normalize 生成命令时保留
+	unsupported []curlArg
+	// 解析过程中发现的非致命问题，例如宽松模式下跳过的未知选项、被忽略的选项、冲突的选项
+	Warnings []Diagnostic
 }
 
 // CurlParser curl解析器
 type CurlParser struct {
 	curlCommand string
+	// 命令所使用的shell方言，默认自动识别
+	dialect Dialect
+	// 严格模式下未知选项、缺少参数和重复选项会导致解析失败
+	strict bool
+	// 读取 @file 引用的文件系统，为nil时不读取文件
+	fsys fs.FS
+	// @- 和 -T - 读取的标准输入，为nil时不读取
+	stdin io.Reader
+	// 已经读取的标准输入内容
+	stdinData []byte
+	stdinRead bool
+}
+
+// Option 解析器选项
+type Option func(*CurlParser)
+
+// WithDialect 指定命令的shell方言，不指定时根据命令内容自动识别
+func WithDialect(dialect Dialect) Option {
+	return func(cp *CurlParser) {
+		cp.dialect = dialect
+	}
+}
+
+// WithStrict 设置是否使用严格模式
+// 严格模式下未知选项、缺少参数和重复的单值选项会返回 *ParseError；
+// 默认的宽松模式下这些问题会被跳过并记录在 HTTPRequest.Warnings 中。
+func WithStrict(strict bool) Option {
+	return func(cp *CurlParser) {
+		cp.strict = strict
+	}
 }
 
 // NewCurlParser 创建新的curl解析器
-func NewCurlParser(curlCommand string) *CurlParser {
-	return &CurlParser{
+func NewCurlParser(curlCommand string, opts ...Option) *CurlParser {
+	cp := &CurlParser{
 		curlCommand: curlCommand,
 	}
+	for _, opt := range opts {
+		opt(cp)
+	}
+	return cp
 }
 
 // Parse 解析curl命令并返回HTTPRequest结构
@@ -63,104 +126,165 @@ func (cp *CurlParser) Parse() (*HTTPRequest, error) {
 		Headers:       make(map[string]string),
 		Query:         make(map[string]string),
 		ParsedCookies: make(map[string]string),
+		fsys:          cp.fsys,
 	}
 
-	// 清理curl命令，移除多余的空白字符和换行符
-	// cmd := strings.ReplaceAll(cp.curlCommand, "\\\n", " ")
-	// cmd = strings.ReplaceAll(cmd, "\\", "")
-	// cmd = strings.TrimSpace(cmd)
-	cmd := cp.curlCommand
+	// 先像shell一样把命令切分为argv，再按顺序识别每个选项，
+	// 这样选项的参数（例如请求头的值）不会被误认为其他选项
+	// 所有错误都是 *ParseError，直接返回以便调用方通过 errors.As 获取位置信息
+	args, err := cp.parseArgs(req)
+	if err != nil {
+		return nil, err
+	}
 
-	// 移除开头的curl
-	if strings.HasPrefix(cmd, "curl ") {
-		cmd = strings.TrimPrefix(cmd, "curl ")
+	// 展开 -H @file 引用的请求头文件
+	args, err = cp.expandHeaderFiles(args, req)
+	if err != nil {
+		return nil, err
 	}
 
 	// 解析URL
-	urlStr, err := cp.extractURL(cmd)
+	urlStr, err := cp.extractURL(args)
 	if err != nil {
-		return nil, fmt.Errorf("解析URL失败: %v", err)
+		return nil, err
 	}
 	req.URL = urlStr
 
-	// 解析BaseURL和Path
-	parsedURL, err := url.Parse(urlStr)
-	if err == nil {
-		req.BaseURL = fmt.Sprintf("%s://%s", parsedURL.Scheme, parsedURL.Host)
-		req.Path = parsedURL.Path
-	}
-
 	// 解析HTTP方法
-	req.Method = cp.extractMethod(cmd)
+	req.Method = cp.extractMethod(args)
 
 	// 解析Headers
-	cp.extractHeaders(cmd, req)
+	cp.extractHeaders(args, req)
 
 	// 解析Body
-	req.Body = cp.extractBody(cmd)
+	req.Body, err = cp.extractBody(args, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// 解析 -F 和 --form-string 构成的multipart表单
+	if err := cp.extractMultipart(args, req); err != nil {
+		return nil, err
+	}
+
+	// 解析 -T 上传的文件，文件内容作为请求体
+	if err := cp.extractUploadFile(args, req); err != nil {
+		return nil, err
+	}
+
+	// -G 把数据作为查询参数追加到URL中
+	if flagEnabled(args, "get") && hasOption(args, dataOptions...) {
+		req.URL = appendQuery(req.URL, req.Body)
+		req.Body = ""
+	}
+
+	// 对请求体分类
+	cp.extractTypedBody(args, req)
+
+	// 解析BaseURL和Path，-T 和 -G 可能修改了URL
+	if parsedURL, err := url.Parse(req.URL); err == nil {
+		req.BaseURL = fmt.Sprintf("%s://%s", parsedURL.Scheme, parsedURL.Host)
+		req.Path = parsedURL.Path
+	}
 
 	// 解析Query参数
 	cp.extractQueryParams(req)
 
 	// 解析Cookie
-	cp.extractCookies(req)
+	cp.extractCookies(args, req)
 
 	// 解析其他参数
-	cp.extractUserAgent(cmd, req)
-	cp.extractAuth(cmd, req)
-	cp.extractReferer(cmd, req)
-	cp.extractProxy(cmd, req)
-	cp.extractTimeouts(cmd, req)
-	cp.extractSSLOptions(cmd, req)
-	cp.extractCookieJar(cmd, req)
-	cp.extractFollowRedirects(cmd, req)
+	cp.extractUserAgent(args, req)
+	cp.extractAuth(args, req)
+	cp.extractReferer(args, req)
+	cp.extractProxy(args, req)
+	if err := cp.extractTimeouts(args, req); err != nil {
+		return nil, err
+	}
+	cp.extractSSLOptions(args, req)
+	cp.extractCookieJar(args, req)
+	cp.extractFollowRedirects(args, req)
+	cp.extractFail(args, req)
+	if err := cp.extractMaxRedirects(args, req); err != nil {
+		return nil, err
+	}
+
+	// 检查被忽略、冲突和可疑的内容
+	cp.diagnose(args, req)
 
 	return req, nil
 }
 
-// extractURL 提取URL
-func (cp *CurlParser) extractURL(cmd string) (string, error) {
-	// 查找第一个URL（以http://或https://开头）
-	// 改进的正则表达式，更好地处理引号包围的URL
-	urlRegex := regexp.MustCompile(`(?:"|'|)(https?://[^\s"']+)("|'|)`)
-	matches := urlRegex.FindStringSubmatch(cmd)
-	if len(matches) > 1 {
-		return matches[1], nil
+// report 处理非致命问题：严格模式下作为错误返回，宽松模式下记录为警告
+func (cp *CurlParser) report(req *HTTPRequest, perr *ParseError) error {
+	if cp.strict {
+		return perr
 	}
+	req.Warnings = append(req.Warnings, perr.diagnostic())
+	return nil
+}
 
-	// 如果上面的方法失败，尝试更宽松的匹配
-	urlRegex = regexp.MustCompile(`(https?://[^\s"']+)`)
-	matches = urlRegex.FindStringSubmatch(cmd)
-	if len(matches) > 1 {
-		return matches[1], nil
+// extractURL 提取URL
+func (cp *CurlParser) extractURL(args []curlArg) (string, error) {
+	if arg, ok := urlArg(args); ok {
+		return arg.value, nil
 	}
 
-	return "", fmt.Errorf("未找到有效的URL")
+	return "", &ParseError{Code: CodeNoURL, Index: -1, Offset: -1, Message: "未找到有效的URL"}
+}
+
+// urlArg 返回作为URL的参数，即第一个位置参数或 --url 的参数
+// 宽松模式下跳过的未知选项可能带有参数，紧跟在它之后的位置参数只有含有 :// 或者没有其他候选时才作为URL。
+func urlArg(args []curlArg) (curlArg, bool) {
+	var (
+		fallback curlArg
+		found    bool
+	)
+	for _, arg := range args {
+		if !arg.is("", "url") || arg.value == "" {
+			continue
+		}
+		if arg.unknownBefore != "" && !strings.Contains(arg.value, "://") {
+			if !found {
+				fallback, found = arg, true
+			}
+			continue
+		}
+		return arg, true
+	}
+	return fallback, found
 }
 
 // extractMethod 提取HTTP方法
-func (cp *CurlParser) extractMethod(cmd string) string {
-	// 检查是否有-X参数指定方法
-	methodRegex := regexp.MustCompile(`-X\s+(\w+)`)
-	matches := methodRegex.FindStringSubmatch(cmd)
-	if len(matches) > 1 {
-		return strings.ToUpper(matches[1])
+func (cp *CurlParser) extractMethod(args []curlArg) string {
+	// 检查是否有 -X 或 --request 参数指定方法
+	if method, ok := lastValue(args, "request"); ok && method != "" {
+		return strings.ToUpper(method)
+	}
+
+	// -G 把数据放到URL中，请求方法保持GET，与 -I 一起使用时为HEAD
+	if flagEnabled(args, "get") {
+		if flagEnabled(args, "head") {
+			return "HEAD"
+		}
+		return "GET"
 	}
 
-	// 检查是否有--request参数
-	requestRegex := regexp.MustCompile(`--request\s+(\w+)`)
-	matches = requestRegex.FindStringSubmatch(cmd)
-	if len(matches) > 1 {
-		return strings.ToUpper(matches[1])
+	// -T 上传文件使用PUT，-I 只获取响应头使用HEAD
+	if hasOption(args, "upload-file") {
+		return "PUT"
+	}
+	if flagEnabled(args, "head") {
+		return "HEAD"
 	}
 
-	// 检查是否有特定参数（表示POST请求）
-	if strings.Contains(cmd, "--data") || strings.Contains(cmd, "-d") {
+	// 检查是否有发送数据的参数（表示POST请求）
+	if hasOption(args, dataOptions...) {
 		return "POST"
 	}
 
 	// 检查是否有文件上传相关参数
-	if strings.Contains(cmd, "--form") || strings.Contains(cmd, "-F") {
+	if hasOption(args, "form", "form-string") {
 		return "POST"
 	}
 
@@ -168,92 +292,164 @@ func (cp *CurlParser) extractMethod(cmd string) string {
 	return "GET"
 }
 
+// dataOptions 发送请求体数据的选项
+var dataOptions = []string{"data", "data-raw", "data-binary", "data-ascii", "data-urlencode", "json"}
+
 // extractHeaders 提取请求头
-func (cp *CurlParser) extractHeaders(cmd string, req *HTTPRequest) {
-	// 匹配 -H 或 --header 参数，支持多种格式
-	// 1. 单引号包围: -H 'Content-Type: application/json'
-	// 2. 双引号包围: -H "Content-Type: application/json"
-	// 3. 无引号: -H Content-Type:application/json
-	headerRegex := regexp.MustCompile(`(?:-H|--header)\s+(?:'([^']*)'|"([^"]*)"|([^\s]+))`)
-	matches := headerRegex.FindAllStringSubmatch(cmd, -1)
-
-	for _, match := range matches {
-		if len(match) > 3 {
-			// 获取非空的匹配组
-			header := ""
-			for i := 1; i <= 3; i++ {
-				if match[i] != "" {
-					header = match[i]
-					break
-				}
-			}
+func (cp *CurlParser) extractHeaders(args []curlArg, req *HTTPRequest) {
+	// 匹配 -H 或 --header 参数，引号已在分词阶段去除
+	for _, arg := range args {
+		if arg.name != "header" {
+			continue
+		}
 
-			if header != "" {
-				parts := strings.SplitN(header, ":", 2)
-				if len(parts) == 2 {
-					key := strings.TrimSpace(parts[0])
-					value := strings.TrimSpace(parts[1])
-					req.Headers[key] = value
-				}
-			}
+		header, remove, ok := parseHeaderLine(arg.value)
+		switch {
+		case !ok:
+			continue
+		case remove:
+			req.RemovedHeaders = append(req.RemovedHeaders, header.Name)
+		default:
+			req.Headers[header.Name] = header.Value
+			req.HeaderList = append(req.HeaderList, header)
 		}
 	}
+
+	// --json 会让curl添加JSON的Content-Type和Accept，-H 中指定或移除的同名请求头优先
+	if !hasOption(args, "json") {
+		return
+	}
+	for _, header := range jsonHeaders {
+		if req.HeaderList.Has(header.Name) || req.HeaderRemoved(header.Name) {
+			continue
+		}
+		req.Headers[header.Name] = header.Value
+		req.HeaderList = append(req.HeaderList, header)
+	}
 }
 
-// extractBody 提取请求体 - 改进版本
-func (cp *CurlParser) extractBody(cmd string) string {
-	// 先尝试匹配单引号包围的JSON
-	singleQuoteRegex := regexp.MustCompile(`(?:--data|-d)\s+'([^']*)'`)
-	matches := singleQuoteRegex.FindStringSubmatch(cmd)
-	if len(matches) > 1 {
-		return matches[1]
-	}
-
-	// 再尝试匹配双引号包围的JSON
-	doubleQuoteRegex := regexp.MustCompile(`(?:--data|-d)\s+"([^"]*)"`)
-	matches = doubleQuoteRegex.FindStringSubmatch(cmd)
-	if len(matches) > 1 {
-		return matches[1]
-	}
-
-	// 匹配 --data-raw 参数
-	dataRawRegex := regexp.MustCompile(`--data-raw\s+['"]?(.*?)['"]?$`)
-	matches = dataRawRegex.FindStringSubmatch(cmd)
-	if len(matches) > 1 {
-		return matches[1]
-	}
-
-	// 匹配无引号的数据
-	noQuoteRegex := regexp.MustCompile(`(?:--data|-d)\s+([^\s]+)`)
-	matches = noQuoteRegex.FindStringSubmatch(cmd)
-	if len(matches) > 1 {
-		return matches[1]
-	}
-
-	// 匹配 --form 参数
-	formRegex := regexp.MustCompile(`(?:--form|-F)\s+(?:'([^']*)'|"([^"]*)"|([^\s]+))`)
-	formMatches := formRegex.FindAllStringSubmatch(cmd, -1)
-	if len(formMatches) > 0 {
-		// 构建form数据
-		var formData []string
-		for _, match := range formMatches {
-			if len(match) > 3 {
-				formDataStr := ""
-				for i := 1; i <= 3; i++ {
-					if match[i] != "" {
-						formDataStr = match[i]
-						break
-					}
-				}
-				if formDataStr != "" {
-					formData = append(formData, formDataStr)
-				}
-			}
+// HeaderRemoved 判断是否通过 -H "Name:" 移除了curl默认添加的请求头，名称不区分大小写
+func (r *HTTPRequest) HeaderRemoved(name string) bool {
+	for _, removed := range r.RemovedHeaders {
+		if strings.EqualFold(removed, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// extractBody 提取请求体
+func (cp *CurlParser) extractBody(args []curlArg, req *HTTPRequest) (string, error) {
+	// 所有数据参数按出现顺序用 & 连接，与curl一致，
+	// --json 之前不加分隔符，已有内容为空时也不加分隔符
+	var (
+		body  strings.Builder
+		found bool
+	)
+	for _, arg := range args {
+		if !arg.is(dataOptions...) {
+			continue
+		}
+		found = true
+		value, err := cp.dataValue(req, arg)
+		if err != nil {
+			return "", err
+		}
+		if body.Len() > 0 && arg.name != "json" {
+			body.WriteByte('&')
+		}
+		body.WriteString(value)
+	}
+	if found {
+		return body.String(), nil
+	}
+
+	// 匹配 --form 参数，构建form数据
+	var formData []string
+	for _, arg := range args {
+		if arg.is("form", "form-string") && arg.value != "" {
+			formData = append(formData, arg.value)
 		}
-		return strings.Join(formData, "&")
+	}
+	return strings.Join(formData, "&"), nil
+}
+
+// extractUploadFile 提取 -T 上传的文件
+// URL没有路径或以 / 结尾时，curl会把文件名追加到URL中；文件通过 WithFS 读取，无法读取时记录为未解析的引用。
+func (cp *CurlParser) extractUploadFile(args []curlArg, req *HTTPRequest) error {
+	arg, ok := firstArg(args, "upload-file")
+	if !ok {
+		return nil
+	}
+	req.UploadFile = arg.value
+	if arg.value != "-" {
+		req.URL = appendFileName(req.URL, arg.value)
+	}
+
+	data, ok, err := cp.loadFile(req, arg, arg.value)
+	if err != nil || !ok {
+		return err
+	}
+	req.Body = string(data)
+	return nil
+}
+
+// appendFileName 在URL的路径为空或以 / 结尾时追加编码后的文件名，与curl上传文件时的行为一致
+func appendFileName(rawURL, file string) string {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil || parsedURL.Path != "" && !strings.HasSuffix(parsedURL.Path, "/") {
+		return rawURL
 	}
 
-	return ""
+	name := file[strings.LastIndexAny(file, `/\`)+1:]
+	if name == "" {
+		return rawURL
+	}
+	name = urlEscape(name)
+	if parsedURL.Path == "" {
+		name = "/" + name
+	}
+
+	end := strings.IndexAny(rawURL, "?#")
+	if end < 0 {
+		end = len(rawURL)
+	}
+	return rawURL[:end] + name + rawURL[end:]
+}
+
+// appendQuery 把查询字符串追加到URL中，URL已有查询参数时用 & 连接
+// 片段（#之后的部分）保持在最后。
+func appendQuery(rawURL, query string) string {
+	if query == "" {
+		return rawURL
+	}
+	fragment := ""
+	if i := strings.IndexByte(rawURL, '#'); i >= 0 {
+		rawURL, fragment = rawURL[:i], rawURL[i:]
+	}
+	sep := "?"
+	if strings.Contains(rawURL, "?") {
+		sep = "&"
+	}
+	return rawURL + sep + escapeQuery(query) + fragment
+}
+
+// escapeQuery 编码查询字符串中不能直接出现在URL里的字节，与libcurl处理URL的方式一致
+// 空格、控制字符和非ASCII字节编码为 %XX，# 也需要编码以免被当作片段；已经编码的 %XX 保持不变。
+func escapeQuery(query string) string {
+	const hex = "0123456789ABCDEF"
+	var buf strings.Builder
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		if c > ' ' && c < 0x7f && c != '#' {
+			buf.WriteByte(c)
+			continue
+		}
+		buf.WriteByte('%')
+		buf.WriteByte(hex[c>>4])
+		buf.WriteByte(hex[c&0x0f])
+	}
+	return buf.String()
 }
 
 // extractQueryParams 从URL中提取查询参数
@@ -272,18 +468,14 @@ func (cp *CurlParser) extractQueryParams(req *HTTPRequest) {
 }
 
 // extractCookies 从Headers中提取并解析Cookie
-func (cp *CurlParser) extractCookies(req *HTTPRequest) {
+func (cp *CurlParser) extractCookies(args []curlArg, req *HTTPRequest) {
 	// 首先尝试从 -b 或 --cookie 参数中提取Cookie
-	cookieData := cp.extractCookieFromParams(req)
+	cookieData := cp.extractCookieFromParams(args)
 
 	// 如果没有从参数中找到，则从Headers中获取Cookie头
+	// 名称不区分大小写，多个Cookie头合并在一起
 	if cookieData == "" {
-		cookieHeader, exists := req.Headers["Cookie"]
-		if !exists {
-			// 也检查小写的cookie头
-			cookieHeader, exists = req.Headers["cookie"]
-		}
-		cookieData = cookieHeader
+		cookieData = strings.Join(req.HeaderList.Values("Cookie"), "; ")
 	}
 
 	if cookieData == "" {
@@ -315,171 +507,116 @@ func (cp *CurlParser) extractCookies(req *HTTPRequest) {
 }
 
 // extractCookieFromParams 从 -b 或 --cookie 参数中提取Cookie数据
-func (cp *CurlParser) extractCookieFromParams(req *HTTPRequest) string {
-	cmd := cp.curlCommand
-
-	// 匹配 -b 或 --cookie 参数
-	// 支持多种格式：
-	// 1. -b "name1=value1; name2=value2"
-	// 2. -b 'name1=value1; name2=value2'
-	// 3. --cookie "name1=value1; name2=value2"
-	// 4. --cookie 'name1=value1; name2=value2'
-	// 5. -b name1=value1;name2=value2 (无引号)
-
-	// 匹配单引号包围的cookie
-	singleQuoteRegex := regexp.MustCompile(`(?:-b|--cookie)\s+'([^']*)'`)
-	matches := singleQuoteRegex.FindStringSubmatch(cmd)
-	if len(matches) > 1 {
-		return matches[1]
-	}
-
-	// 匹配双引号包围的cookie
-	doubleQuoteRegex := regexp.MustCompile(`(?:-b|--cookie)\s+"([^"]*)"`)
-	matches = doubleQuoteRegex.FindStringSubmatch(cmd)
-	if len(matches) > 1 {
-		return matches[1]
-	}
-
-	// 匹配无引号的cookie（到下一个参数或行尾）
-	noQuoteRegex := regexp.MustCompile(`(?:-b|--cookie)\s+([^\s-][^\s]*(?:\s+[^\s-][^\s]*)*?)(?:\s+-|$|\s+https?://)`)
-	matches = noQuoteRegex.FindStringSubmatch(cmd)
-	if len(matches) > 1 {
-		return strings.TrimSpace(matches[1])
+func (cp *CurlParser) extractCookieFromParams(args []curlArg) string {
+	// 多个 -b 参数会被curl用 "; " 连接后一起发送
+	var cookies []string
+	for _, arg := range args {
+		if arg.name == "cookie" && strings.TrimSpace(arg.value) != "" {
+			cookies = append(cookies, strings.TrimSpace(arg.value))
+		}
 	}
-
-	return ""
+	return strings.Join(cookies, "; ")
 }
 
 // extractUserAgent 提取User-Agent
-func (cp *CurlParser) extractUserAgent(cmd string, req *HTTPRequest) {
+func (cp *CurlParser) extractUserAgent(args []curlArg, req *HTTPRequest) {
 	// 匹配 -A 或 --user-agent 参数
-	// 支持格式: -A "Mozilla/5.0" 或 --user-agent 'Custom Agent'
-	userAgentRegex := regexp.MustCompile(`(?:-A|--user-agent)\s+(?:'([^']*)'|"([^"]*)"|([^\s-][^\s]*(?:\s+[^\s-][^\s]*)*?)(?:\s+-|$|\s+https?://))`)
-	matches := userAgentRegex.FindStringSubmatch(cmd)
-	if len(matches) > 3 {
-		// 获取非空的匹配组
-		for i := 1; i <= 3; i++ {
-			if matches[i] != "" {
-				req.UserAgent = strings.TrimSpace(matches[i])
-				break
-			}
-		}
+	if userAgent, ok := lastValue(args, "user-agent"); ok {
+		req.UserAgent = strings.TrimSpace(userAgent)
 	}
 }
 
 // extractAuth 提取认证信息
-func (cp *CurlParser) extractAuth(cmd string, req *HTTPRequest) {
+func (cp *CurlParser) extractAuth(args []curlArg, req *HTTPRequest) {
 	// 匹配 -u 或 --user 参数
-	// 支持格式: -u "username:password" 或 --user admin:secret
-	authRegex := regexp.MustCompile(`(?:-u|--user)\s+(?:'([^']*)'|"([^"]*)"|([^\s-][^\s]*(?:\s+[^\s-][^\s]*)*?)(?:\s+-|$|\s+https?://))`)
-	matches := authRegex.FindStringSubmatch(cmd)
-	if len(matches) > 3 {
-		// 获取非空的匹配组
-		for i := 1; i <= 3; i++ {
-			if matches[i] != "" {
-				req.Auth = strings.TrimSpace(matches[i])
-				break
-			}
-		}
+	if auth, ok := lastValue(args, "user"); ok {
+		req.Auth = strings.TrimSpace(auth)
 	}
 }
 
 // extractReferer 提取Referer
-func (cp *CurlParser) extractReferer(cmd string, req *HTTPRequest) {
-	// 匹配 --referer 参数
-	// 支持格式: --referer "https://example.com" 或 --referer 'https://example.com'
-	refererRegex := regexp.MustCompile(`--referer\s+(?:'([^']*)'|"([^"]*)"|([^\s-][^\s]*(?:\s+[^\s-][^\s]*)*?)(?:\s+-|$))`)
-	matches := refererRegex.FindStringSubmatch(cmd)
-	if len(matches) > 3 {
-		// 获取非空的匹配组
-		for i := 1; i <= 3; i++ {
-			if matches[i] != "" {
-				req.Referer = strings.TrimSpace(matches[i])
-				break
-			}
-		}
+func (cp *CurlParser) extractReferer(args []curlArg, req *HTTPRequest) {
+	// 匹配 -e 或 --referer 参数
+	if referer, ok := lastValue(args, "referer"); ok {
+		req.Referer = strings.TrimSpace(referer)
 	}
 }
 
 // extractProxy 提取代理信息
-func (cp *CurlParser) extractProxy(cmd string, req *HTTPRequest) {
-	// 匹配 --proxy 参数
-	// 支持格式: --proxy "http://proxy:8080" 或 --proxy 'socks5://proxy:1080'
-	proxyRegex := regexp.MustCompile(`--proxy\s+(?:'([^']*)'|"([^"]*)"|([^\s-][^\s]*(?:\s+[^\s-][^\s]*)*?)(?:\s+-|$))`)
-	matches := proxyRegex.FindStringSubmatch(cmd)
-	if len(matches) > 3 {
-		// 获取非空的匹配组
-		for i := 1; i <= 3; i++ {
-			if matches[i] != "" {
-				req.Proxy = strings.TrimSpace(matches[i])
-				break
-			}
-		}
+func (cp *CurlParser) extractProxy(args []curlArg, req *HTTPRequest) {
+	// 匹配 -x 或 --proxy 参数
+	if proxy, ok := lastValue(args, "proxy"); ok {
+		req.Proxy = strings.TrimSpace(proxy)
 	}
 }
 
 // extractTimeouts 提取超时设置
-func (cp *CurlParser) extractTimeouts(cmd string, req *HTTPRequest) {
-	// 匹配 --connect-timeout 参数
-	connectTimeoutRegex := regexp.MustCompile(`--connect-timeout\s+(\d+)`)
-	matches := connectTimeoutRegex.FindStringSubmatch(cmd)
-	if len(matches) > 1 {
-		if timeout, err := fmt.Sscanf(matches[1], "%d", &req.ConnectTimeout); err == nil && timeout == 1 {
-			// 成功解析
+func (cp *CurlParser) extractTimeouts(args []curlArg, req *HTTPRequest) error {
+	// curl允许小数形式的秒数，这里向上取整，避免不足一秒的超时变成不超时
+	for _, arg := range args {
+		if !arg.is("connect-timeout", "max-time") {
+			continue
 		}
-	}
 
-	// 匹配 --max-time 参数
-	maxTimeRegex := regexp.MustCompile(`--max-time\s+(\d+)`)
-	matches = maxTimeRegex.FindStringSubmatch(cmd)
-	if len(matches) > 1 {
-		if timeout, err := fmt.Sscanf(matches[1], "%d", &req.MaxTime); err == nil && timeout == 1 {
-			// 成功解析
+		timeout, err := strconv.ParseFloat(arg.value, 64)
+		if err != nil || !(timeout >= 0 && timeout <= math.MaxInt32) {
+			return arg.errorf(CodeInvalidNumber, "无效的秒数 %q", arg.value)
+		}
+		if arg.name == "connect-timeout" {
+			req.ConnectTimeout = int(math.Ceil(timeout))
+		} else {
+			req.MaxTime = int(math.Ceil(timeout))
 		}
 	}
+	return nil
 }
 
 // extractSSLOptions 提取SSL选项
-func (cp *CurlParser) extractSSLOptions(cmd string, req *HTTPRequest) {
-	// 检查 --insecure 参数
-	if strings.Contains(cmd, "--insecure") {
-		req.Insecure = true
-	}
+func (cp *CurlParser) extractSSLOptions(args []curlArg, req *HTTPRequest) {
+	// 检查 -k 或 --insecure 参数
+	req.Insecure = flagEnabled(args, "insecure")
 
 	// 匹配 --cacert 参数
-	cacertRegex := regexp.MustCompile(`--cacert\s+(?:'([^']*)'|"([^"]*)"|([^\s-][^\s]*(?:\s+[^\s-][^\s]*)*?)(?:\s+-|$|\s+https?://))`)
-	matches := cacertRegex.FindStringSubmatch(cmd)
-	if len(matches) > 3 {
-		// 获取非空的匹配组
-		for i := 1; i <= 3; i++ {
-			if matches[i] != "" {
-				req.CACert = strings.TrimSpace(matches[i])
-				break
-			}
-		}
+	if cacert, ok := lastValue(args, "cacert"); ok {
+		req.CACert = strings.TrimSpace(cacert)
 	}
 }
 
 // extractCookieJar 提取Cookie文件路径
-func (cp *CurlParser) extractCookieJar(cmd string, req *HTTPRequest) {
+func (cp *CurlParser) extractCookieJar(args []curlArg, req *HTTPRequest) {
 	// 匹配 -c 或 --cookie-jar 参数
-	cookieJarRegex := regexp.MustCompile(`(?:-c|--cookie-jar)\s+(?:'([^']*)'|"([^"]*)"|([^\s-][^\s]*(?:\s+[^\s-][^\s]*)*?)(?:\s+-|$|\s+https?://))`)
-	matches := cookieJarRegex.FindStringSubmatch(cmd)
-	if len(matches) > 3 {
-		// 获取非空的匹配组
-		for i := 1; i <= 3; i++ {
-			if matches[i] != "" {
-				req.CookieJar = strings.TrimSpace(matches[i])
-				break
-			}
-		}
+	if jar, ok := lastValue(args, "cookie-jar"); ok {
+		req.CookieJar = strings.TrimSpace(jar)
 	}
 }
 
 // extractFollowRedirects 提取重定向设置
-func (cp *CurlParser) extractFollowRedirects(cmd string, req *HTTPRequest) {
-	// 检查 -L 或 --location 参数
-	if strings.Contains(cmd, "-L") || strings.Contains(cmd, "--location") {
-		req.FollowRedirects = true
+func (cp *CurlParser) extractFollowRedirects(args []curlArg, req *HTTPRequest) {
+	// 检查 -L、--location 或 --location-trusted 参数
+	req.FollowRedirects = flagEnabled(args, "location", "location-trusted")
+}
+
+// extractFail 提取 -f/--fail 设置
+func (cp *CurlParser) extractFail(args []curlArg, req *HTTPRequest) {
+	req.FailOnError = flagEnabled(args, "fail", "fail-with-body")
+}
+
+// defaultMaxRedirects curl默认的最大重定向次数
+const defaultMaxRedirects = 50
+
+// extractMaxRedirects 提取最大重定向次数
+func (cp *CurlParser) extractMaxRedirects(args []curlArg, req *HTTPRequest) error {
+	req.MaxRedirects = defaultMaxRedirects
+	for _, arg := range args {
+		if arg.name != "max-redirs" {
+			continue
+		}
+		// 与curl一致，-1表示不限制
+		maxRedirects, err := strconv.Atoi(arg.value)
+		if err != nil || maxRedirects < -1 {
+			return arg.errorf(CodeInvalidNumber, "无效的重定向次数 %q", arg.value)
+		}
+		req.MaxRedirects = maxRedirects
 	}
+	return nil
 }
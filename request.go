@@ -0,0 +1,259 @@
+package curl_parser
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"mime"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// DefaultUserAgent 没有指定 -A 时curl发送的User-Agent
+const DefaultUserAgent = "curl/8.7.1"
+
+// ToHTTPRequest 生成可以直接发送的 *http.Request
+// 与curl一致，没有指定时会添加 User-Agent、Accept: */* 以及 -d 使用的 application/x-www-form-urlencoded，
+// 通过 -H "Name:" 移除的请求头不会发送。-F 表单的文件通过解析时 WithFS 指定的文件系统读取，
+// 请求体引用了没有读取的文件时返回错误。代理、超时等传输选项见 NewHTTPClient。
+func (r *HTTPRequest) ToHTTPRequest(ctx context.Context) (*http.Request, error) {
+	body, contentType, err := r.requestBody()
+	if err != nil {
+		return nil, err
+	}
+
+	rawURL := r.URL
+	if !strings.Contains(rawURL, "://") {
+		// curl对没有协议的URL默认使用http
+		rawURL = "http://" + rawURL
+	}
+	method := r.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, method, rawURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("创建HTTP请求失败: %w", err)
+	}
+
+	header := httpReq.Header
+	for _, h := range r.HeaderList {
+		header.Add(h.Name, h.Value)
+	}
+
+	// curl默认添加的请求头，-H 指定或移除的同名请求头优先
+	defaults := []Header{
+		{Name: "User-Agent", Value: DefaultUserAgent},
+		{Name: "Accept", Value: "*/*"},
+		{Name: "Referer", Value: strings.TrimSuffix(r.Referer, ";auto")},
+		{Name: "Content-Type", Value: contentType},
+	}
+	if r.UserAgent != "" {
+		defaults[0].Value = r.UserAgent
+	}
+	for _, h := range defaults {
+		if h.Value == "" || r.HeaderList.Has(h.Name) || r.HeaderRemoved(h.Name) {
+			continue
+		}
+		header.Set(h.Name, h.Value)
+	}
+	// -H 指定了不带boundary的multipart类型时，curl会补上生成的boundary
+	if r.IsMultipart {
+		_, params, _ := mime.ParseMediaType(contentType)
+		if userType := r.HeaderList.Get("Content-Type"); strings.HasPrefix(strings.ToLower(userType), "multipart/") && !strings.Contains(userType, "boundary=") {
+			header.Set("Content-Type", userType+"; boundary="+params["boundary"])
+		}
+	}
+	if r.HeaderRemoved("User-Agent") && !r.HeaderList.Has("User-Agent") {
+		// net/http 在没有User-Agent时会添加自己的值，设置为空才不会发送
+		header.Set("User-Agent", "")
+	}
+
+	// -b 指定的Cookie作为单独的Cookie请求头发送，不含 = 的参数是curl读取的Cookie文件
+	if strings.Contains(r.RawCookie, "=") && r.RawCookie != strings.Join(r.HeaderList.Values("Cookie"), "; ") {
+		header.Add("Cookie", r.RawCookie)
+	}
+
+	if r.Auth != "" && !r.HeaderList.Has("Authorization") {
+		username, password, _ := strings.Cut(r.Auth, ":")
+		httpReq.SetBasicAuth(username, password)
+	}
+
+	return httpReq, nil
+}
+
+// requestBody 返回实际发送的请求体以及curl默认使用的Content-Type
+func (r *HTTPRequest) requestBody() ([]byte, string, error) {
+	typed := r.TypedBody
+	if typed == nil {
+		// 没有经过 Parse 构造的请求直接发送 Body
+		return []byte(r.Body), "", nil
+	}
+
+	switch typed.Kind {
+	case BodyMultipart:
+		return r.MultipartBody(r.fsys, "")
+	case BodyFile:
+		return nil, "", fmt.Errorf("请求体引用的文件 %s 没有读取，需要在解析时使用 WithFS", typed.File)
+	case BodyStdin:
+		return nil, "", errors.New("请求体引用了标准输入，需要在解析时使用 WithStdin")
+	}
+
+	// 发送 Body 而不是解析时的 TypedBody.Raw，解析后修改 Body 同样生效
+	// -T 上传文件时curl不添加Content-Type
+	if r.UploadFile != "" {
+		return []byte(r.Body), "", nil
+	}
+	return []byte(r.Body), formContentType, nil
+}
+
+// FromHTTPRequest 将 *http.Request 转换为HTTPRequest，是 ToHTTPRequest 的逆操作
+// 请求体读取后会恢复，请求仍然可以发送。client 不为nil时，Transport的代理和证书验证
+// 转换为 Proxy、Insecure，Timeout 转换为 MaxTime（向上取整），没有设置 CheckRedirect 时与curl -L --max-redirs 10 相同。
+// 请求头按名称排序；有请求体但没有Content-Type时移除curl默认添加的Content-Type。
+func FromHTTPRequest(req *http.Request, client *http.Client) (*HTTPRequest, error) {
+	body, err := readHTTPBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	r := convertHTTPRequest(req, body)
+	if client != nil {
+		if err := r.applyTransport(client.Transport, req); err != nil {
+			return nil, err
+		}
+		if client.Timeout > 0 {
+			r.MaxTime = int(math.Ceil(client.Timeout.Seconds()))
+		}
+		if client.CheckRedirect == nil {
+			// net/http 默认最多跟随10次重定向
+			r.FollowRedirects, r.MaxRedirects = true, 10
+		}
+	}
+	return r, nil
+}
+
+// convertHTTPRequest 转换请求行、请求头和已经读取的请求体，body为nil表示没有请求体
+func convertHTTPRequest(req *http.Request, body []byte) *HTTPRequest {
+	method := req.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	r := &HTTPRequest{
+		Method:        method,
+		URL:           req.URL.String(),
+		BaseURL:       fmt.Sprintf("%s://%s", req.URL.Scheme, req.URL.Host),
+		Path:          req.URL.Path,
+		Headers:       make(map[string]string),
+		Query:         make(map[string]string),
+		ParsedCookies: make(map[string]string),
+		Body:          string(body),
+		MaxRedirects:  defaultMaxRedirects,
+	}
+	for key, values := range req.URL.Query() {
+		r.Query[key] = values[0]
+	}
+
+	if req.Host != "" && req.Host != req.URL.Host {
+		r.HeaderList = append(r.HeaderList, Header{Name: "Host", Value: req.Host})
+	}
+	names := make([]string, 0, len(req.Header))
+	for name := range req.Header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		values := req.Header[name]
+		if len(values) == 0 || name == "User-Agent" && values[0] == "" {
+			// 值为空的User-Agent表示不发送
+			r.RemovedHeaders = append(r.RemovedHeaders, name)
+			continue
+		}
+		for _, value := range values {
+			r.HeaderList = append(r.HeaderList, Header{Name: name, Value: value})
+			r.Headers[name] = value
+		}
+	}
+
+	if body != nil {
+		contentType := r.HeaderList.Get("Content-Type")
+		if contentType == "" {
+			r.RemovedHeaders = append(r.RemovedHeaders, "Content-Type")
+		}
+		r.TypedBody = &Body{
+			Kind:        classifyBody(contentType, contentType != "", body),
+			Raw:         body,
+			Option:      "--data-raw",
+			ContentType: contentType,
+		}
+	}
+
+	if cookies := r.HeaderList.Values("Cookie"); len(cookies) > 0 {
+		r.RawCookie = strings.Join(cookies, "; ")
+		for _, cookie := range req.Cookies() {
+			r.ParsedCookies[cookie.Name] = cookie.Value
+		}
+	}
+
+	return r
+}
+
+// readHTTPBody 读取请求体并恢复，没有请求体时返回nil
+func readHTTPBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, fmt.Errorf("读取请求体失败: %w", err)
+		}
+		defer body.Close()
+		data, err := io.ReadAll(body)
+		if err != nil {
+			return nil, fmt.Errorf("读取请求体失败: %w", err)
+		}
+		return data, nil
+	}
+
+	data, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("读取请求体失败: %w", err)
+	}
+	req.Body = io.NopCloser(bytes.NewReader(data))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+	return data, nil
+}
+
+// applyTransport 将Transport的代理和证书验证设置转换为 --proxy、--insecure
+// rt 为nil时使用 http.DefaultTransport，不是 *http.Transport 时忽略。
+func (r *HTTPRequest) applyTransport(rt http.RoundTripper, req *http.Request) error {
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	transport, ok := rt.(*http.Transport)
+	if !ok {
+		return nil
+	}
+
+	if transport.Proxy != nil {
+		proxyURL, err := transport.Proxy(req)
+		if err != nil {
+			return fmt.Errorf("获取代理失败: %w", err)
+		}
+		if proxyURL != nil {
+			r.Proxy = proxyURL.String()
+		}
+	}
+	if transport.TLSClientConfig != nil && transport.TLSClientConfig.InsecureSkipVerify {
+		r.Insecure = true
+	}
+	return nil
+}
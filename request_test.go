@@ -0,0 +1,266 @@
+package curl_parser
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestHTTPRequest_ToHTTPRequest(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a.txt": {Data: []byte("file")},
+	}
+
+	tests := []struct {
+		name       string
+		cmd        string
+		wantMethod string
+		wantURL    string
+		wantHeader http.Header
+		wantBody   string
+	}{
+		{
+			name:       "Curl defaults",
+			cmd:        `curl example.com/p?q=1`,
+			wantMethod: "GET",
+			wantURL:    "http://example.com/p?q=1",
+			wantHeader: http.Header{"User-Agent": {DefaultUserAgent}, "Accept": {"*/*"}},
+		},
+		{
+			name:       "Form data content type",
+			cmd:        `curl -d a=1 -d b=2 https://a.com`,
+			wantMethod: "POST",
+			wantURL:    "https://a.com",
+			wantHeader: http.Header{
+				"User-Agent":   {DefaultUserAgent},
+				"Accept":       {"*/*"},
+				"Content-Type": {"application/x-www-form-urlencoded"},
+			},
+			wantBody: "a=1&b=2",
+		},
+		{
+			name:       "User options and headers",
+			cmd:        `curl -A 'app/1.0' -e 'https://ref;auto' -u 'user:p:w' -b 'a=1' -H 'Cookie: b=2' -H 'X-Multi: 1' -H 'x-multi: 2' --json '{}' https://a.com`,
+			wantMethod: "POST",
+			wantURL:    "https://a.com",
+			wantHeader: http.Header{
+				"User-Agent":    {"app/1.0"},
+				"Accept":        {"application/json"},
+				"Referer":       {"https://ref"},
+				"Authorization": {"Basic dXNlcjpwOnc="},
+				"Cookie":        {"b=2", "a=1"},
+				"X-Multi":       {"1", "2"},
+				"Content-Type":  {"application/json"},
+			},
+			wantBody: "{}",
+		},
+		{
+			name:       "Removed and explicit headers win",
+			cmd:        `curl -H 'User-Agent:' -H 'Accept:' -H 'Authorization: Bearer t' -u a:b -d x -H 'Content-Type: text/plain' https://a.com`,
+			wantMethod: "POST",
+			wantURL:    "https://a.com",
+			wantHeader: http.Header{
+				"User-Agent":    {""},
+				"Authorization": {"Bearer t"},
+				"Content-Type":  {"text/plain"},
+			},
+			wantBody: "x",
+		},
+		{
+			name:       "Cookie file is not a header",
+			cmd:        `curl -b cookies.txt https://a.com`,
+			wantMethod: "GET",
+			wantURL:    "https://a.com",
+			wantHeader: http.Header{"User-Agent": {DefaultUserAgent}, "Accept": {"*/*"}},
+		},
+		{
+			name:       "Upload has no content type",
+			cmd:        `curl -T a.txt https://a.com/up/`,
+			wantMethod: "PUT",
+			wantURL:    "https://a.com/up/a.txt",
+			wantHeader: http.Header{"User-Agent": {DefaultUserAgent}, "Accept": {"*/*"}},
+			wantBody:   "file",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := NewCurlParser(tt.cmd, WithFS(fsys)).Parse()
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			httpReq, err := req.ToHTTPRequest(context.Background())
+			if err != nil {
+				t.Fatalf("ToHTTPRequest() error = %v", err)
+			}
+			if httpReq.Method != tt.wantMethod {
+				t.Errorf("Method = %s, want %s", httpReq.Method, tt.wantMethod)
+			}
+			if httpReq.URL.String() != tt.wantURL {
+				t.Errorf("URL = %s, want %s", httpReq.URL, tt.wantURL)
+			}
+			if !reflect.DeepEqual(httpReq.Header, tt.wantHeader) {
+				t.Errorf("Header = %v, want %v", httpReq.Header, tt.wantHeader)
+			}
+			body, _ := io.ReadAll(httpReq.Body)
+			if string(body) != tt.wantBody {
+				t.Errorf("Body = %q, want %q", body, tt.wantBody)
+			}
+			if httpReq.ContentLength != int64(len(tt.wantBody)) {
+				t.Errorf("ContentLength = %d, want %d", httpReq.ContentLength, len(tt.wantBody))
+			}
+		})
+	}
+}
+
+func TestHTTPRequest_ToHTTPRequestMultipart(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a.txt": {Data: []byte("file")},
+	}
+	req, err := NewCurlParser(`curl -F f=@a.txt -F b=1 -H 'Content-Type: multipart/mixed' https://a.com`, WithFS(fsys)).Parse()
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	httpReq, err := req.ToHTTPRequest(context.Background())
+	if err != nil {
+		t.Fatalf("ToHTTPRequest() error = %v", err)
+	}
+
+	body, contentType, _ := req.MultipartBody(fsys, "")
+	_, boundary, _ := strings.Cut(contentType, "boundary=")
+	if got := httpReq.Header.Get("Content-Type"); got != "multipart/mixed; boundary="+boundary {
+		t.Errorf("Content-Type = %s", got)
+	}
+	got, _ := io.ReadAll(httpReq.Body)
+	if string(got) != string(body) {
+		t.Errorf("Body = %q, want %q", got, body)
+	}
+}
+
+func TestHTTPRequest_ToHTTPRequestUnresolvedBody(t *testing.T) {
+	for _, cmd := range []string{
+		`curl --data-binary @a.bin https://a.com`,
+		`curl -d @- https://a.com`,
+		`curl -F f=@a.bin https://a.com`,
+	} {
+		req, err := NewCurlParser(cmd).Parse()
+		if err != nil {
+			t.Fatalf("Parse() error = %v", err)
+		}
+		if _, err := req.ToHTTPRequest(context.Background()); err == nil {
+			t.Errorf("ToHTTPRequest(%s) error = nil, want error", cmd)
+		}
+	}
+}
+
+func TestFromHTTPRequest(t *testing.T) {
+	httpReq, _ := http.NewRequest("POST", "https://a.com/p?q=1", io.NopCloser(strings.NewReader(`{"a":1}`)))
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Add("Cookie", "a=1")
+	httpReq.Header.Add("Cookie", "b=2")
+	httpReq.Header.Set("User-Agent", "")
+	httpReq.Host = "b.com"
+
+	proxyURL, _ := url.Parse("http://127.0.0.1:8080")
+	client := &http.Client{
+		Transport: &http.Transport{
+			Proxy:           http.ProxyURL(proxyURL),
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+		Timeout: 1500 * time.Millisecond,
+	}
+
+	req, err := FromHTTPRequest(httpReq, client)
+	if err != nil {
+		t.Fatalf("FromHTTPRequest() error = %v", err)
+	}
+
+	want := &HTTPRequest{
+		Method:  "POST",
+		URL:     "https://a.com/p?q=1",
+		BaseURL: "https://a.com",
+		Path:    "/p",
+		Headers: map[string]string{"Content-Type": "application/json", "Cookie": "b=2"},
+		HeaderList: HeaderList{
+			{Name: "Host", Value: "b.com"},
+			{Name: "Content-Type", Value: "application/json"},
+			{Name: "Cookie", Value: "a=1"},
+			{Name: "Cookie", Value: "b=2"},
+		},
+		RemovedHeaders:  []string{"User-Agent"},
+		Body:            `{"a":1}`,
+		TypedBody:       &Body{Kind: BodyJSON, Raw: []byte(`{"a":1}`), Option: "--data-raw", ContentType: "application/json"},
+		Query:           map[string]string{"q": "1"},
+		RawCookie:       "a=1; b=2",
+		ParsedCookies:   map[string]string{"a": "1", "b": "2"},
+		Proxy:           "http://127.0.0.1:8080",
+		MaxTime:         2,
+		Insecure:        true,
+		FollowRedirects: true,
+		MaxRedirects:    10,
+	}
+	if !reflect.DeepEqual(req, want) {
+		t.Errorf("FromHTTPRequest() =\n%+v\nwant\n%+v", req, want)
+	}
+
+	// 请求体读取后可以再次读取
+	body, _ := io.ReadAll(httpReq.Body)
+	if string(body) != `{"a":1}` {
+		t.Errorf("Body after FromHTTPRequest = %q", body)
+	}
+}
+
+func TestFromHTTPRequest_BodyWithoutContentType(t *testing.T) {
+	httpReq, _ := http.NewRequest("PUT", "https://a.com", strings.NewReader("raw"))
+
+	req, err := FromHTTPRequest(httpReq, nil)
+	if err != nil {
+		t.Fatalf("FromHTTPRequest() error = %v", err)
+	}
+	if !req.HeaderRemoved("Content-Type") {
+		t.Errorf("RemovedHeaders = %v, want Content-Type", req.RemovedHeaders)
+	}
+
+	// 转换回 *http.Request 时与原来的请求一致
+	back, err := req.ToHTTPRequest(context.Background())
+	if err != nil {
+		t.Fatalf("ToHTTPRequest() error = %v", err)
+	}
+	if back.Method != "PUT" || back.Header.Get("Content-Type") != "" {
+		t.Errorf("ToHTTPRequest() = %s %v", back.Method, back.Header)
+	}
+	body, _ := io.ReadAll(back.Body)
+	if string(body) != "raw" {
+		t.Errorf("Body = %q, want raw", body)
+	}
+}
+
+func TestHTTPRequest_ToHTTPRequestEditedBody(t *testing.T) {
+	for _, cmd := range []string{
+		`curl -d a=1 https://a.com`,
+		`curl -T a.txt https://a.com/`,
+	} {
+		req, err := NewCurlParser(cmd, WithFS(fstest.MapFS{"a.txt": {Data: []byte("a=1")}})).Parse()
+		if err != nil {
+			t.Fatalf("Parse() error = %v", err)
+		}
+
+		// 解析后修改的请求体同样会发送
+		req.Body = "edited=2"
+		httpReq, err := req.ToHTTPRequest(context.Background())
+		if err != nil {
+			t.Fatalf("ToHTTPRequest() error = %v", err)
+		}
+		body, _ := io.ReadAll(httpReq.Body)
+		if string(body) != "edited=2" || httpReq.ContentLength != int64(len("edited=2")) {
+			t.Errorf("ToHTTPRequest(%s) Body = %q, ContentLength = %d", cmd, body, httpReq.ContentLength)
+		}
+	}
+}
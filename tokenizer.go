@@ -0,0 +1,569 @@
+package curl_parser
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// token 表示分词得到的一个参数及其在原始命令中的字节偏移
+type token struct {
+	value  string
+	offset int
+	// value中每个字节在原始命令中的位置，转义得到的字节对应转义序列的开头
+	offsets []int
+}
+
+// offsetOf 返回value中第i个字节在原始命令中的位置
+func (t token) offsetOf(i int) int {
+	if i < len(t.offsets) {
+		return t.offsets[i]
+	}
+	return t.offset + i
+}
+
+// tokenBuilder 拼接token的内容，同时记录每个字节在原始命令中的位置
+type tokenBuilder struct {
+	buf []byte
+	pos []int
+}
+
+// writeByte 写入位于at处的一个字节
+func (b *tokenBuilder) writeByte(c byte, at int) {
+	b.buf = append(b.buf, c)
+	b.pos = append(b.pos, at)
+}
+
+// writeString 写入原始命令中从at处开始的一段内容
+func (b *tokenBuilder) writeString(s string, at int) {
+	for i := 0; i < len(s); i++ {
+		b.writeByte(s[i], at+i)
+	}
+}
+
+// writeDecoded 写入at处的转义序列解码得到的内容
+func (b *tokenBuilder) writeDecoded(s string, at int) {
+	for i := 0; i < len(s); i++ {
+		b.writeByte(s[i], at)
+	}
+}
+
+// token 返回拼接好的token并清空内容
+func (b *tokenBuilder) token(offset int) token {
+	t := token{value: string(b.buf), offset: offset, offsets: b.pos}
+	b.buf, b.pos = nil, nil
+	return t
+}
+
+// unterminatedQuote 构造引号未闭合的错误，index为未闭合参数在argv中的下标，offset为引号的位置
+func unterminatedQuote(index, offset int, message string) *ParseError {
+	return &ParseError{Code: CodeUnterminatedQuote, Index: index, Offset: offset, Message: message}
+}
+
+// tokenizeBash 按照bash的规则把命令切分成argv
+// 支持单引号、双引号（含反斜杠转义）、反斜杠换行续行以及相邻引号片段拼接。
+// 由于只做分词而不执行命令，; & | 等控制字符按普通字符处理，
+// 这样未加引号的URL（如 https://a.com/?x=1&y=2）也能被完整保留。
+func tokenizeBash(cmd string) ([]token, error) {
+	var (
+		tokens []token
+		buf    tokenBuilder
+		start  = -1 // 当前token的起始位置，-1表示不在token中
+	)
+
+	mark := func(i int) {
+		if start < 0 {
+			start = i
+		}
+	}
+	flush := func() {
+		if start >= 0 {
+			tokens = append(tokens, buf.token(start))
+			start = -1
+		}
+	}
+
+	for i := 0; i < len(cmd); {
+		c := cmd[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			flush()
+			i++
+
+		case c == '#' && start < 0:
+			// 只有出现在单词开头的#才是注释，一直到行尾
+			for i < len(cmd) && cmd[i] != '\n' {
+				i++
+			}
+
+		case c == '\\':
+			// 反斜杠换行是续行符，不产生任何字符
+			if strings.HasPrefix(cmd[i+1:], "\n") {
+				i += 2
+				continue
+			}
+			if strings.HasPrefix(cmd[i+1:], "\r\n") {
+				i += 3
+				continue
+			}
+			mark(i)
+			if i+1 >= len(cmd) {
+				// 命令末尾的反斜杠按字面处理
+				buf.writeByte('\\', i)
+				i++
+				continue
+			}
+			buf.writeByte(cmd[i+1], i+1)
+			i += 2
+
+		case c == '$' && strings.HasPrefix(cmd[i+1:], "'"):
+			// $'...' 是ANSI-C引号，浏览器的 "Copy as cURL (bash)" 会用它表示含引号或控制字符的数据
+			mark(i)
+			j, ok := readANSIC(cmd, i+1, &buf)
+			if !ok {
+				return nil, unterminatedQuote(len(tokens), i, "单引号未闭合")
+			}
+			i = j
+
+		case c == '$' && strings.HasPrefix(cmd[i+1:], `"`):
+			// $"..." 是本地化字符串，不做翻译时与双引号相同
+			mark(i)
+			j, ok := readDoubleQuoted(cmd, i+1, &buf)
+			if !ok {
+				return nil, unterminatedQuote(len(tokens), i, "双引号未闭合")
+			}
+			i = j
+
+		case c == '\'':
+			mark(i)
+			end := strings.IndexByte(cmd[i+1:], '\'')
+			if end < 0 {
+				return nil, unterminatedQuote(len(tokens), i, "单引号未闭合")
+			}
+			buf.writeString(cmd[i+1:i+1+end], i+1)
+			i += end + 2
+
+		case c == '"':
+			mark(i)
+			j, ok := readDoubleQuoted(cmd, i, &buf)
+			if !ok {
+				return nil, unterminatedQuote(len(tokens), i, "双引号未闭合")
+			}
+			i = j
+
+		default:
+			mark(i)
+			buf.writeByte(c, i)
+			i++
+		}
+	}
+	flush()
+
+	return tokens, nil
+}
+
+// readDoubleQuoted 读取从pos处开始的双引号字符串，返回闭合引号之后的位置，引号未闭合时返回false
+// 双引号内反斜杠只转义 $ ` " \ 和换行，其余情况保留反斜杠本身。
+func readDoubleQuoted(cmd string, pos int, buf *tokenBuilder) (int, bool) {
+	for j := pos + 1; j < len(cmd); j++ {
+		switch cmd[j] {
+		case '"':
+			return j + 1, true
+		case '\\':
+			if j+1 >= len(cmd) {
+				buf.writeByte('\\', j)
+				continue
+			}
+			switch next := cmd[j+1]; next {
+			case '$', '`', '"', '\\':
+				buf.writeByte(next, j+1)
+				j++
+			case '\n':
+				j++
+			default:
+				buf.writeByte('\\', j)
+			}
+		default:
+			buf.writeByte(cmd[j], j)
+		}
+	}
+	return 0, false
+}
+
+// readANSIC 读取从pos处开始的 $'...' 中的单引号部分，返回闭合引号之后的位置，引号未闭合时返回false
+// 支持的转义与bash一致：\a \b \e \f \n \r \t \v \\ \' \" \? \nnn \xHH \uHHHH \UHHHHHHHH \cX
+func readANSIC(cmd string, pos int, buf *tokenBuilder) (int, bool) {
+	for j := pos + 1; j < len(cmd); j++ {
+		c := cmd[j]
+		if c == '\'' {
+			return j + 1, true
+		}
+		if c != '\\' || j+1 >= len(cmd) {
+			buf.writeByte(c, j)
+			continue
+		}
+
+		at := j
+		j++
+		switch esc := cmd[j]; esc {
+		case 'a':
+			buf.writeByte('\a', at)
+		case 'b':
+			buf.writeByte('\b', at)
+		case 'e', 'E':
+			buf.writeByte(0x1b, at)
+		case 'f':
+			buf.writeByte('\f', at)
+		case 'n':
+			buf.writeByte('\n', at)
+		case 'r':
+			buf.writeByte('\r', at)
+		case 't':
+			buf.writeByte('\t', at)
+		case 'v':
+			buf.writeByte('\v', at)
+		case '\\', '\'', '"', '?':
+			buf.writeByte(esc, at)
+		case 'c':
+			// \cX 表示控制字符 Ctrl-X
+			if j+1 < len(cmd) {
+				j++
+				buf.writeByte(cmd[j]&0x1f, at)
+			} else {
+				buf.writeString("\\c", at)
+			}
+		case '0', '1', '2', '3', '4', '5', '6', '7':
+			digits := scanDigits(cmd[j:], 3, 8)
+			n, _ := strconv.ParseUint(digits, 8, 16)
+			buf.writeByte(byte(n), at)
+			j += len(digits) - 1
+		case 'x':
+			digits := scanDigits(cmd[j+1:], 2, 16)
+			if digits == "" {
+				buf.writeString("\\x", at)
+				continue
+			}
+			n, _ := strconv.ParseUint(digits, 16, 8)
+			buf.writeByte(byte(n), at)
+			j += len(digits)
+		case 'u', 'U':
+			size := 4
+			if esc == 'U' {
+				size = 8
+			}
+			digits := scanDigits(cmd[j+1:], size, 16)
+			if digits == "" {
+				buf.writeString(cmd[at:j+1], at)
+				continue
+			}
+			n, _ := strconv.ParseUint(digits, 16, 32)
+			j += len(digits)
+			r := rune(n)
+			// 浏览器按UTF-16逐个转义字符，代理对需要合并成一个字符
+			if utf16.IsSurrogate(r) && strings.HasPrefix(cmd[j+1:], "\\u") {
+				low := scanDigits(cmd[j+3:], 4, 16)
+				if m, err := strconv.ParseUint(low, 16, 32); err == nil && len(low) == 4 {
+					if combined := utf16.DecodeRune(r, rune(m)); combined != utf8.RuneError {
+						r = combined
+						j += 2 + len(low)
+					}
+				}
+			}
+			buf.writeDecoded(string(r), at)
+		default:
+			// 未知转义保留反斜杠
+			buf.writeString(cmd[at:j+1], at)
+		}
+	}
+	return 0, false
+}
+
+// scanDigits 返回s开头最多limit个指定进制的数字
+func scanDigits(s string, limit int, base int) string {
+	n := 0
+	for n < len(s) && n < limit {
+		c := s[n]
+		var ok bool
+		switch base {
+		case 8:
+			ok = c >= '0' && c <= '7'
+		default:
+			ok = (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+		}
+		if !ok {
+			break
+		}
+		n++
+	}
+	return s[:n]
+}
+
+// tokenizeCmd 按照Windows cmd.exe的规则把命令切分为argv
+// Windows上的参数要经过两轮解析：cmd.exe先处理 ^ 转义、^ 续行和 %% 转义，
+// 然后curl.exe按照MS C运行库的规则处理双引号和反斜杠并切分参数。
+func tokenizeCmd(cmd string) ([]token, error) {
+	// 第一轮：cmd.exe，pos记录每个输出字节在原始命令中的位置
+	var (
+		line    []byte
+		pos     []int
+		inQuote bool
+	)
+	emit := func(c byte, i int) {
+		line = append(line, c)
+		pos = append(pos, i)
+	}
+	for i := 0; i < len(cmd); i++ {
+		c := cmd[i]
+		switch {
+		case c == '"':
+			inQuote = !inQuote
+			emit(c, i)
+
+		case c == '^' && !inQuote:
+			if i+1 >= len(cmd) {
+				continue
+			}
+			i++
+			if strings.HasPrefix(cmd[i:], "\r\n") {
+				i++
+			}
+			if cmd[i] == '\n' {
+				// 行尾的 ^ 是续行符，下一行的第一个字符按字面处理
+				if i+1 < len(cmd) {
+					i++
+					emit(cmd[i], i)
+				}
+				continue
+			}
+			emit(cmd[i], i)
+
+		case c == '%' && strings.HasPrefix(cmd[i+1:], "%"):
+			emit('%', i)
+			i++
+
+		default:
+			emit(c, i)
+		}
+	}
+
+	// 第二轮：MS C运行库的argv规则
+	var (
+		tokens []token
+		buf    tokenBuilder
+		start  = -1
+	)
+	inQuote = false
+	quoteStart := 0
+	for i := 0; i < len(line); {
+		c := line[i]
+		switch {
+		case (c == ' ' || c == '\t' || c == '\r' || c == '\n') && !inQuote:
+			if start >= 0 {
+				tokens = append(tokens, buf.token(pos[start]))
+				start = -1
+			}
+			i++
+
+		case c == '\\':
+			if start < 0 {
+				start = i
+			}
+			// 2n个反斜杠加引号得到n个反斜杠，引号用于切换引号状态；
+			// 2n+1个反斜杠加引号得到n个反斜杠和一个字面引号。
+			// 浏览器生成命令时会把所有反斜杠加倍，所以其余情况下成对的反斜杠也合并为一个，
+			// 单独的反斜杠（如 C:\tmp）按字面处理
+			n := 0
+			for i+n < len(line) && line[i+n] == '\\' {
+				n++
+			}
+			i += n
+			if i < len(line) && line[i] == '"' {
+				buf.writeDecoded(strings.Repeat("\\", n/2), pos[i-n])
+				if n%2 == 1 {
+					buf.writeByte('"', pos[i])
+					i++
+				}
+			} else {
+				buf.writeDecoded(strings.Repeat("\\", n/2+n%2), pos[i-n])
+			}
+
+		case c == '"':
+			if start < 0 {
+				start = i
+			}
+			if inQuote && i+1 < len(line) && line[i+1] == '"' {
+				// 引号内连续两个双引号表示一个字面引号
+				buf.writeByte('"', pos[i])
+				i += 2
+				continue
+			}
+			if !inQuote {
+				quoteStart = i
+			}
+			inQuote = !inQuote
+			i++
+
+		default:
+			if start < 0 {
+				start = i
+			}
+			buf.writeByte(c, pos[i])
+			i++
+		}
+	}
+	if inQuote {
+		return nil, unterminatedQuote(len(tokens), pos[quoteStart], "双引号未闭合")
+	}
+	if start >= 0 {
+		tokens = append(tokens, buf.token(pos[start]))
+	}
+
+	return tokens, nil
+}
+
+// tokenizePowerShell 按照PowerShell的规则把命令切分为argv
+// 反引号是转义符，行尾的反引号是续行符；单引号字符串中连续两个单引号表示一个单引号，
+// 双引号字符串中连续两个双引号表示一个双引号。$变量不做展开。
+func tokenizePowerShell(cmd string) ([]token, error) {
+	var (
+		tokens []token
+		buf    tokenBuilder
+		start  = -1
+	)
+
+	mark := func(i int) {
+		if start < 0 {
+			start = i
+		}
+	}
+	flush := func() {
+		if start >= 0 {
+			tokens = append(tokens, buf.token(start))
+			start = -1
+		}
+	}
+
+	for i := 0; i < len(cmd); {
+		c := cmd[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			flush()
+			i++
+
+		case c == '#' && start < 0:
+			for i < len(cmd) && cmd[i] != '\n' {
+				i++
+			}
+
+		case c == '`':
+			// 反引号换行是续行符
+			if strings.HasPrefix(cmd[i+1:], "\n") {
+				i += 2
+				continue
+			}
+			if strings.HasPrefix(cmd[i+1:], "\r\n") {
+				i += 3
+				continue
+			}
+			mark(i)
+			i = writePowerShellEscape(cmd, i, &buf)
+
+		case c == '\'':
+			mark(i)
+			j := i + 1
+			for {
+				end := strings.IndexByte(cmd[j:], '\'')
+				if end < 0 {
+					return nil, unterminatedQuote(len(tokens), i, "单引号未闭合")
+				}
+				buf.writeString(cmd[j:j+end], j)
+				j += end + 1
+				// 单引号字符串中连续两个单引号表示一个字面单引号
+				if !strings.HasPrefix(cmd[j:], "'") {
+					break
+				}
+				buf.writeByte('\'', j-1)
+				j++
+			}
+			i = j
+
+		case c == '"':
+			mark(i)
+			j := i + 1
+			for {
+				if j >= len(cmd) {
+					return nil, unterminatedQuote(len(tokens), i, "双引号未闭合")
+				}
+				if cmd[j] == '`' && j+1 < len(cmd) {
+					j = writePowerShellEscape(cmd, j, &buf)
+					continue
+				}
+				if cmd[j] == '"' {
+					// 双引号字符串中连续两个双引号表示一个字面双引号
+					if strings.HasPrefix(cmd[j+1:], `"`) {
+						buf.writeByte('"', j)
+						j += 2
+						continue
+					}
+					j++
+					break
+				}
+				buf.writeByte(cmd[j], j)
+				j++
+			}
+			i = j
+
+		default:
+			mark(i)
+			buf.writeByte(c, i)
+			i++
+		}
+	}
+	flush()
+
+	return tokens, nil
+}
+
+// writePowerShellEscape 处理pos处以反引号开头的转义序列，返回转义序列之后的位置
+func writePowerShellEscape(cmd string, pos int, buf *tokenBuilder) int {
+	if pos+1 >= len(cmd) {
+		buf.writeByte('`', pos)
+		return pos + 1
+	}
+
+	switch esc := cmd[pos+1]; esc {
+	case '0':
+		buf.writeByte(0, pos)
+	case 'a':
+		buf.writeByte('\a', pos)
+	case 'b':
+		buf.writeByte('\b', pos)
+	case 'e':
+		buf.writeByte(0x1b, pos)
+	case 'f':
+		buf.writeByte('\f', pos)
+	case 'n':
+		buf.writeByte('\n', pos)
+	case 'r':
+		buf.writeByte('\r', pos)
+	case 't':
+		buf.writeByte('\t', pos)
+	case 'v':
+		buf.writeByte('\v', pos)
+	case 'u':
+		// `u{XXXX} 表示Unicode字符
+		if strings.HasPrefix(cmd[pos+2:], "{") {
+			if end := strings.IndexByte(cmd[pos+3:], '}'); end > 0 {
+				if n, err := strconv.ParseUint(cmd[pos+3:pos+3+end], 16, 32); err == nil {
+					buf.writeDecoded(string(rune(n)), pos)
+					return pos + 4 + end
+				}
+			}
+		}
+		buf.writeByte(esc, pos)
+	default:
+		buf.writeByte(esc, pos)
+	}
+	return pos + 2
+}
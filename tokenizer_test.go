@@ -0,0 +1,275 @@
+package curl_parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenizeBash(t *testing.T) {
+	tests := []struct {
+		name    string
+		cmd     string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "Plain words",
+			cmd:  "curl -X GET https://httpbin.org/get",
+			want: []string{"curl", "-X", "GET", "https://httpbin.org/get"},
+		},
+		{
+			name: "Single quotes keep everything literal",
+			cmd:  `curl -d '{"a":"\n $x"}'`,
+			want: []string{"curl", "-d", `{"a":"\n $x"}`},
+		},
+		{
+			name: "Double quotes with escapes",
+			cmd:  `curl -H "X-A: \"q\" \\ \$HOME \n"`,
+			want: []string{"curl", "-H", `X-A: "q" \ $HOME \n`},
+		},
+		{
+			name: "Backslash newline continuation",
+			cmd:  "curl \\\n  -L \\\r\n  https://a.com",
+			want: []string{"curl", "-L", "https://a.com"},
+		},
+		{
+			name: "Adjacent segments are concatenated",
+			cmd:  `curl a'b'"c"\ d`,
+			want: []string{"curl", "abc d"},
+		},
+		{
+			name: "Empty quoted word",
+			cmd:  `curl -d '' https://a.com`,
+			want: []string{"curl", "-d", "", "https://a.com"},
+		},
+		{
+			name: "Control characters are literal",
+			cmd:  `curl https://a.com/?x=1&y=2;z|w`,
+			want: []string{"curl", "https://a.com/?x=1&y=2;z|w"},
+		},
+		{
+			name: "Comments",
+			cmd:  "curl https://a.com/#frag # trailing comment\n -L",
+			want: []string{"curl", "https://a.com/#frag", "-L"},
+		},
+		{
+			name: "ANSI-C quoting",
+			cmd:  `curl --data-raw $'{"a":"line\nbreak","b":"it\'s","c":"\\d"}'`,
+			want: []string{"curl", "--data-raw", "{\"a\":\"line\nbreak\",\"b\":\"it's\",\"c\":\"\\d\"}"},
+		},
+		{
+			name: "ANSI-C numeric escapes",
+			cmd:  `curl $'\x41\t\101\u00e9\U0001F600\ud83d\ude00\cA\q'`,
+			want: []string{"curl", "A\tA\u00e9\U0001F600\U0001F600\x01\\q"},
+		},
+		{
+			name: "ANSI-C quoting concatenated with other segments",
+			cmd:  `curl -H 'X-A: '$'\'''b'`,
+			want: []string{"curl", "-H", "X-A: 'b"},
+		},
+		{
+			name:    "Unterminated ANSI-C quote",
+			cmd:     `curl $'abc\'`,
+			wantErr: true,
+		},
+		{
+			name:    "Unterminated single quote",
+			cmd:     `curl -d 'abc`,
+			wantErr: true,
+		},
+		{
+			name:    "Unterminated double quote",
+			cmd:     `curl -d "abc`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, err := tokenizeBash(tt.cmd)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("tokenizeBash() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			got := make([]string, 0, len(tokens))
+			for _, tok := range tokens {
+				got = append(got, tok.value)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tokenizeBash() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokenizeBash_Offsets(t *testing.T) {
+	cmd := `curl -H 'A: b' "https://a.com"`
+	tokens, err := tokenizeBash(cmd)
+	if err != nil {
+		t.Fatalf("tokenizeBash() error = %v", err)
+	}
+	want := []int{0, 5, 8, 15}
+	for i, tok := range tokens {
+		if tok.offset != want[i] {
+			t.Errorf("token %d offset = %d, want %d", i, tok.offset, want[i])
+		}
+	}
+}
+
+func TestTokenize_ByteOffsets(t *testing.T) {
+	tests := []struct {
+		name    string
+		cmd     string
+		dialect Dialect
+		want    []int
+	}{
+		{name: "Bash quotes", cmd: `x'a b'"c"\d`, dialect: DialectBash, want: []int{0, 2, 3, 4, 7, 10}},
+		{name: "Bash ANSI-C escapes", cmd: `$'\x41\n'z`, dialect: DialectBash, want: []int{2, 6, 9}},
+		{name: "Cmd carets", cmd: `^"a^%b^"`, dialect: DialectCmd, want: []int{2, 4, 5}},
+		{name: "PowerShell quotes", cmd: "'it''s'`t", dialect: DialectPowerShell, want: []int{1, 2, 3, 5, 7}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, err := tokenize(tt.cmd, tt.dialect)
+			if err != nil {
+				t.Fatalf("tokenize() error = %v", err)
+			}
+			if len(tokens) != 1 {
+				t.Fatalf("tokenize() = %v, want one token", tokens)
+			}
+			got := make([]int, len(tokens[0].value))
+			for i := range got {
+				got[i] = tokens[0].offsetOf(i)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("offsets of %q = %v, want %v", tokens[0].value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokenizeCmd(t *testing.T) {
+	tests := []struct {
+		name    string
+		cmd     string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "Caret escaped quotes and continuations",
+			cmd:  "curl ^\"https://a.com/?x=1^&y=2^\" ^\n  -H ^\"accept: */*^\"",
+			want: []string{"curl", "https://a.com/?x=1&y=2", "-H", "accept: */*"},
+		},
+		{
+			name: "Escaped JSON body",
+			cmd:  `curl --data-raw ^"^{^\^"a^\^":^\^"say ^\^\^\^"hi^\^\^\^"^\^"^}^"`,
+			want: []string{"curl", "--data-raw", `{"a":"say \"hi\""}`},
+		},
+		{
+			name: "Caret newline keeps a literal newline",
+			cmd:  "curl -d ^\"a^\n\nb^\"",
+			want: []string{"curl", "-d", "a\nb"},
+		},
+		{
+			name: "Plain double quotes and percent escaping",
+			cmd:  `curl.exe -H "X-A: 100%% a^b" -d "say ""hi""" https://a.com`,
+			want: []string{"curl.exe", "-H", "X-A: 100% a^b", "-d", `say "hi"`, "https://a.com"},
+		},
+		{
+			name: "Backslashes not followed by quotes",
+			cmd:  `curl -d C:\tmp\\x "a\\" https://a.com`,
+			want: []string{"curl", "-d", `C:\tmp\x`, `a\`, "https://a.com"},
+		},
+		{
+			name:    "Unterminated quote",
+			cmd:     `curl -d "abc`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, err := tokenizeCmd(tt.cmd)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("tokenizeCmd() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			got := make([]string, 0, len(tokens))
+			for _, tok := range tokens {
+				got = append(got, tok.value)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tokenizeCmd() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokenizePowerShell(t *testing.T) {
+	tests := []struct {
+		name    string
+		cmd     string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "Backtick continuations",
+			cmd:  "curl.exe -H \"A: b\" `\n  -L `\r\n  https://a.com",
+			want: []string{"curl.exe", "-H", "A: b", "-L", "https://a.com"},
+		},
+		{
+			name: "Single quote doubling",
+			cmd:  `curl.exe -d 'it''s' https://a.com`,
+			want: []string{"curl.exe", "-d", "it's", "https://a.com"},
+		},
+		{
+			name: "Double quote escapes",
+			cmd:  "curl.exe -d \"say `\"hi`\" and \"\"bye\"\" `$HOME`t`u{e9}\" https://a.com",
+			want: []string{"curl.exe", "-d", "say \"hi\" and \"bye\" $HOME\t\u00e9", "https://a.com"},
+		},
+		{
+			name: "Backslashes are literal",
+			cmd:  `curl.exe -d 'C:\tmp\' https://a.com`,
+			want: []string{"curl.exe", "-d", `C:\tmp\`, "https://a.com"},
+		},
+		{
+			name: "Call operator",
+			cmd:  `& 'C:\Program Files\curl\curl.exe' https://a.com`,
+			want: []string{"&", `C:\Program Files\curl\curl.exe`, "https://a.com"},
+		},
+		{
+			name:    "Unterminated single quote",
+			cmd:     `curl.exe -d 'it''s`,
+			wantErr: true,
+		},
+		{
+			name:    "Unterminated double quote",
+			cmd:     `curl.exe -d "abc`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, err := tokenizePowerShell(tt.cmd)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("tokenizePowerShell() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			got := make([]string, 0, len(tokens))
+			for _, tok := range tokens {
+				got = append(got, tok.value)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tokenizePowerShell() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,217 @@
+package curl_parser
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"sync"
+)
+
+// defaultLogBodyLimit 日志中请求体的默认最大长度
+const defaultLogBodyLimit = 64 << 10
+
+// defaultLogRedactHeaders 日志中默认隐藏的请求头
+var defaultLogRedactHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie"}
+
+// LogOption LoggingTransport的选项
+type LogOption func(*LoggingTransport)
+
+// WithLogger 使用slog输出curl命令，没有指定 WithLogger 和 WithLogWriter 时使用 slog.Default()
+func WithLogger(logger *slog.Logger) LogOption {
+	return func(t *LoggingTransport) {
+		t.logger = logger
+	}
+}
+
+// WithLogLevel 设置slog的日志级别，默认为 slog.LevelInfo
+func WithLogLevel(level slog.Level) LogOption {
+	return func(t *LoggingTransport) {
+		t.level = level
+	}
+}
+
+// WithLogWriter 将curl命令写入w，每个命令之后换行
+func WithLogWriter(w io.Writer) LogOption {
+	return func(t *LoggingTransport) {
+		t.writer = w
+	}
+}
+
+// WithLogBodyLimit 设置请求体的最大长度，超过时截断，默认为64KiB，小于0表示不限制
+func WithLogBodyLimit(n int) LogOption {
+	return func(t *LoggingTransport) {
+		t.bodyLimit = n
+	}
+}
+
+// WithLogRedactHeaders 设置需要隐藏值的请求头，替换默认的 Authorization、Proxy-Authorization 和 Cookie
+// 不传参数表示不隐藏任何请求头。
+func WithLogRedactHeaders(names ...string) LogOption {
+	return func(t *LoggingTransport) {
+		t.redact = names
+	}
+}
+
+// WithLogRedactURLPasswords 设置是否将URL和代理地址中的密码替换为 Redacted，默认为true，与 WithLogRedactHeaders 无关
+func WithLogRedactURLPasswords(redact bool) LogOption {
+	return func(t *LoggingTransport) {
+		t.redactURLs = redact
+	}
+}
+
+// WithLogSampleRate 按比例记录请求，rate 取值为0到1，默认记录所有请求
+func WithLogSampleRate(rate float64) LogOption {
+	return func(t *LoggingTransport) {
+		t.sampleRate = rate
+	}
+}
+
+// WithLogGenerateOptions 设置生成curl命令的选项，例如 WithMultiline()
+func WithLogGenerateOptions(opts ...GenerateOption) LogOption {
+	return func(t *LoggingTransport) {
+		t.generateOpts = opts
+	}
+}
+
+// LoggingTransport 在发送请求之前将请求记录为可以直接执行的curl命令
+// 每次重定向都会单独记录。base 的代理和证书验证设置会转换为 --proxy、--insecure。
+type LoggingTransport struct {
+	base         http.RoundTripper
+	logger       *slog.Logger
+	level        slog.Level
+	writer       io.Writer
+	bodyLimit    int
+	redact       []string
+	redactURLs   bool
+	sampleRate   float64
+	generateOpts []GenerateOption
+	// 保证并发请求写入writer时不会交错
+	mu sync.Mutex
+}
+
+// NewLoggingTransport 创建LoggingTransport，base 为nil时使用 http.DefaultTransport
+func NewLoggingTransport(base http.RoundTripper, opts ...LogOption) *LoggingTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	t := &LoggingTransport{
+		base:       base,
+		level:      slog.LevelInfo,
+		bodyLimit:  defaultLogBodyLimit,
+		redact:     defaultLogRedactHeaders,
+		redactURLs: true,
+		sampleRate: 1,
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	if t.logger == nil && t.writer == nil {
+		t.logger = slog.Default()
+	}
+	return t
+}
+
+// RoundTrip 记录请求后使用 base 发送
+// 为了读取请求体，发送的是请求的副本，原请求不会被修改；读取请求体失败时返回错误。
+func (t *LoggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.sampleRate < 1 && rand.Float64() >= t.sampleRate {
+		return t.base.RoundTrip(req)
+	}
+
+	out := req.Clone(req.Context())
+	body, truncated, err := t.readBody(out)
+	var r *HTTPRequest
+	if err == nil {
+		r = convertHTTPRequest(out, body)
+		err = r.applyTransport(t.base, out)
+	}
+	if err != nil {
+		// RoundTrip 出错时也需要关闭请求体
+		if out.Body != nil {
+			out.Body.Close()
+		}
+		return nil, err
+	}
+	t.log(req.Context(), r, truncated, out.ContentLength)
+	return t.base.RoundTrip(out)
+}
+
+// readBody 读取日志中记录的请求体，最多读取 bodyLimit+1 个字节以判断是否需要截断
+// 没有GetBody时读取的内容会放回请求体前面，剩余部分不会读入内存。
+func (t *LoggingTransport) readBody(out *http.Request) (body []byte, truncated bool, err error) {
+	if t.bodyLimit < 0 {
+		body, err := readHTTPBody(out)
+		return body, false, err
+	}
+	if out.Body == nil || out.Body == http.NoBody {
+		return nil, false, nil
+	}
+
+	src := out.Body
+	if out.GetBody != nil {
+		if src, err = out.GetBody(); err != nil {
+			return nil, false, fmt.Errorf("读取请求体失败: %w", err)
+		}
+		defer src.Close()
+	}
+	body, err = io.ReadAll(io.LimitReader(src, int64(t.bodyLimit)+1))
+	if err != nil {
+		return nil, false, fmt.Errorf("读取请求体失败: %w", err)
+	}
+	if out.GetBody == nil {
+		out.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), out.Body), out.Body}
+	}
+
+	if len(body) > t.bodyLimit {
+		return body[:t.bodyLimit], true, nil
+	}
+	return body, false, nil
+}
+
+// log 输出curl命令，size为请求体的总长度，未知时小于等于0
+func (t *LoggingTransport) log(ctx context.Context, r *HTTPRequest, truncated bool, size int64) {
+	opts := []GenerateOption{WithRedactHeaders(t.redact...)}
+	if t.redactURLs {
+		opts = append(opts, WithRedactURLPasswords())
+	}
+	opts = append(opts, t.generateOpts...)
+	cmd := Generate(r, opts...)
+
+	if t.logger != nil {
+		g := &generator{}
+		for _, opt := range opts {
+			opt(g)
+		}
+		attrs := []slog.Attr{
+			slog.String("method", r.Method),
+			slog.String("url", g.url(r.URL)),
+			slog.String("curl", cmd),
+		}
+		if truncated {
+			if size > 0 {
+				attrs = append(attrs, slog.Int64("body_size", size))
+			}
+			attrs = append(attrs, slog.Bool("body_truncated", true))
+		}
+		t.logger.LogAttrs(ctx, t.level, "curl", attrs...)
+	}
+
+	if t.writer != nil {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		switch {
+		case truncated && size > 0:
+			fmt.Fprintf(t.writer, "# 请求体共 %d 字节，只保留前 %d 字节\n", size, t.bodyLimit)
+		case truncated:
+			fmt.Fprintf(t.writer, "# 请求体超过 %d 字节，已截断\n", t.bodyLimit)
+		}
+		fmt.Fprintln(t.writer, cmd)
+	}
+}